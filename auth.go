@@ -0,0 +1,319 @@
+package templit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// AuthMethod selects how DefaultGitClient authenticates against a host.
+type AuthMethod string
+
+const (
+	// AuthNone clones without authentication.
+	AuthNone AuthMethod = "none"
+	// AuthToken sends Token as HTTP basic auth password.
+	AuthToken AuthMethod = "token"
+	// AuthSSHAgent clones over ssh using the keys of the running ssh agent.
+	AuthSSHAgent AuthMethod = "ssh-agent"
+	// AuthSSHKey clones over ssh using the private key in KeyFile.
+	AuthSSHKey AuthMethod = "ssh-key"
+	// AuthNetrc reads HTTP basic auth credentials for the host from a netrc file.
+	AuthNetrc AuthMethod = "netrc"
+	// AuthCredentialHelper asks a git credential helper for HTTP basic auth credentials.
+	AuthCredentialHelper AuthMethod = "credential-helper"
+)
+
+// Credential configures how DefaultGitClient authenticates against a host.
+type Credential struct {
+	Method AuthMethod `json:"method"`
+	// Username is the HTTP basic auth user for AuthToken or the ssh user for ssh methods.
+	Username string `json:"username,omitempty"`
+	// Token is the HTTP basic auth password for AuthToken.
+	Token string `json:"token,omitempty"`
+	// TokenEnv is the environment variable Token is read from when Token is empty.
+	TokenEnv string `json:"token_env,omitempty"`
+	// KeyFile is the private key used by AuthSSHKey.
+	KeyFile string `json:"key_file,omitempty"`
+	// Passphrase decrypts KeyFile.
+	Passphrase string `json:"passphrase,omitempty"`
+	// NetrcFile is the netrc file used by AuthNetrc. It defaults to ~/.netrc.
+	NetrcFile string `json:"netrc_file,omitempty"`
+	// Helper is the git credential helper used by AuthCredentialHelper.
+	// It follows git's credential.helper syntax and defaults to `git credential fill`.
+	Helper string `json:"helper,omitempty"`
+}
+
+// Endpoint returns the clone URL and authentication for a repository.
+// host may be prefixed with a scheme and user as returned by DepInfo.Remote.
+// Rewrites are applied before credentials are looked up, so credentials always
+// belong to the host that is actually contacted. Hosts configured with an ssh
+// method are always cloned over ssh.
+func (d *DefaultGitClient) Endpoint(host, owner, repo string) (string, transport.AuthMethod, error) {
+	target := d.rewrite(host + "/" + owner + "/" + repo)
+	if !strings.Contains(target, "://") {
+		target = "https://" + target
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse repository URL %s: %w", target, err)
+	}
+
+	u.Path = "/" + strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git") + ".git"
+
+	auth, err := d.auth(u)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return u.String(), auth, nil
+}
+
+// auth returns the authentication for the repository URL u. u is switched to ssh for hosts configured with
+// an ssh method.
+func (d *DefaultGitClient) auth(u *url.URL) (transport.AuthMethod, error) {
+	cred, ok := d.credential(u.Host, strings.Trim(u.Path, "/"))
+	if !ok {
+		switch {
+		case u.Scheme == "ssh":
+			cred.Method = AuthSSHAgent
+		case d.Token != "":
+			cred = Credential{Method: AuthToken, Token: d.Token}
+		default:
+			cred.Method = AuthNone
+		}
+	}
+
+	if cred.Method == AuthSSHAgent || cred.Method == AuthSSHKey {
+		u.Scheme = "ssh"
+		if cred.Username != "" {
+			u.User = url.User(cred.Username)
+		}
+		if u.User == nil {
+			u.User = url.User("git")
+		}
+	}
+
+	auth, err := cred.auth(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate against %s: %w", u.Host, err)
+	}
+
+	return auth, nil
+}
+
+// credential returns the credential for the repository at repoPath on host.
+// Credentials keyed by host and owner prefix take precedence over those keyed by host only.
+func (d *DefaultGitClient) credential(host, repoPath string) (Credential, bool) {
+	target := host + "/" + strings.TrimSuffix(repoPath, ".git")
+
+	var match string
+	var cred Credential
+	var found bool
+	for key, c := range d.Credentials {
+		key = strings.TrimSuffix(key, "/")
+		if target != key && !strings.HasPrefix(target, key+"/") {
+			continue
+		}
+
+		if !found || len(key) > len(match) {
+			match, cred, found = key, c, true
+		}
+	}
+
+	if found && cred.Token == "" && cred.TokenEnv != "" {
+		cred.Token = os.Getenv(cred.TokenEnv)
+	}
+
+	return cred, found
+}
+
+// rewrite applies the rewrite rule with the longest matching prefix to target.
+// Rules match target with or without its scheme and user.
+func (d *DefaultGitClient) rewrite(target string) string {
+	bare := cacheHost(target)
+
+	var match Rewrite
+	var rest string
+	for _, rule := range d.Rewrites {
+		if rule.InsteadOf == "" || len(rule.InsteadOf) <= len(match.InsteadOf) {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(target, rule.InsteadOf):
+			match, rest = rule, target[len(rule.InsteadOf):]
+		case strings.HasPrefix(bare, rule.InsteadOf):
+			match, rest = rule, bare[len(rule.InsteadOf):]
+		}
+	}
+
+	if match.InsteadOf == "" {
+		return target
+	}
+
+	return match.URL + rest
+}
+
+// auth returns the go-git authentication for the repository at u.
+func (c Credential) auth(u *url.URL) (transport.AuthMethod, error) {
+	switch c.Method {
+	case AuthNone, "":
+		return nil, nil
+	case AuthToken:
+		username := c.Username
+		if username == "" {
+			username = "username" // this can be anything except an empty string
+		}
+		return &http.BasicAuth{Username: username, Password: c.Token}, nil
+	case AuthSSHAgent:
+		return gitssh.NewSSHAgentAuth(u.User.Username())
+	case AuthSSHKey:
+		return gitssh.NewPublicKeysFromFile(u.User.Username(), expandHome(c.KeyFile), c.Passphrase)
+	case AuthNetrc:
+		file := c.NetrcFile
+		if file == "" {
+			file = "~/.netrc"
+		}
+		username, password, err := readNetrc(expandHome(file), u.Hostname())
+		if err != nil {
+			return nil, err
+		}
+		return &http.BasicAuth{Username: username, Password: password}, nil
+	case AuthCredentialHelper:
+		username, password, err := credentialFill(c.Helper, u)
+		if err != nil {
+			return nil, err
+		}
+		return &http.BasicAuth{Username: username, Password: password}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth method %q", c.Method)
+	}
+}
+
+// readNetrc returns the login and password for machine from the netrc file at path.
+// The default entry is used when there is no entry for machine.
+func readNetrc(path, machine string) (string, string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read netrc: %w", err)
+	}
+
+	type entry struct{ login, password string }
+	var found, fallback *entry
+	var current *entry
+
+	fields := strings.Fields(string(content))
+	for i := 0; i < len(fields); i++ {
+		next := func() string {
+			if i+1 < len(fields) {
+				i++
+				return fields[i]
+			}
+			return ""
+		}
+
+		switch fields[i] {
+		case "machine":
+			current = &entry{}
+			if next() == machine && found == nil {
+				found = current
+			}
+		case "default":
+			current = &entry{}
+			if fallback == nil {
+				fallback = current
+			}
+		case "login":
+			if current != nil {
+				current.login = next()
+			}
+		case "password":
+			if current != nil {
+				current.password = next()
+			}
+		case "account":
+			next()
+		}
+	}
+
+	if found == nil {
+		found = fallback
+	}
+
+	if found == nil {
+		return "", "", fmt.Errorf("no netrc entry for %s in %s", machine, path)
+	}
+
+	return found.login, found.password, nil
+}
+
+// credentialFill asks a git credential helper for the username and password of the repository at u.
+func credentialFill(helper string, u *url.URL) (string, string, error) {
+	var cmd *exec.Cmd
+	switch {
+	case helper == "":
+		cmd = exec.Command("git", "credential", "fill")
+	case strings.HasPrefix(helper, "!"):
+		cmd = exec.Command("sh", "-c", helper[1:]+" get")
+	case filepath.IsAbs(helper):
+		cmd = exec.Command("sh", "-c", helper+" get")
+	default:
+		cmd = exec.Command("sh", "-c", "git credential-"+helper+" get")
+	}
+
+	var input bytes.Buffer
+	fmt.Fprintf(&input, "protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/"))
+
+	cmd.Stdin = &input
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to run credential helper: %w", err)
+	}
+
+	var username, password string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		switch key {
+		case "username":
+			username = value
+		case "password":
+			password = value
+		}
+	}
+
+	if password == "" {
+		return "", "", errors.New("credential helper returned no password")
+	}
+
+	return username, password, nil
+}
+
+// expandHome replaces a leading ~ in path with the home directory of the current user.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,150 @@
+package templit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/euforic/templit"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestDefaultGitClient_Endpoint tests the Endpoint function.
+func TestDefaultGitClient_Endpoint(t *testing.T) {
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	content := "machine other.com login nope password nope\nmachine example.com\n  login alice\n  password secret\n"
+	if err := os.WriteFile(netrc, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write netrc: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		host         string
+		token        string
+		credentials  map[string]templit.Credential
+		rewrites     []templit.Rewrite
+		expectedURL  string
+		expectedAuth transport.AuthMethod
+		wantErr      bool
+	}{
+		{
+			name:        "anonymous https",
+			host:        "example.com",
+			expectedURL: "https://example.com/owner/repo.git",
+		},
+		{
+			name:         "token for hosts without credentials",
+			host:         "example.com",
+			token:        "abc",
+			expectedURL:  "https://example.com/owner/repo.git",
+			expectedAuth: &http.BasicAuth{Username: "username", Password: "abc"},
+		},
+		{
+			name:  "host credentials take precedence over token",
+			host:  "example.com",
+			token: "abc",
+			credentials: map[string]templit.Credential{
+				"example.com": {Method: templit.AuthNone},
+			},
+			expectedURL: "https://example.com/owner/repo.git",
+		},
+		{
+			name: "netrc",
+			host: "example.com",
+			credentials: map[string]templit.Credential{
+				"example.com": {Method: templit.AuthNetrc, NetrcFile: netrc},
+			},
+			expectedURL:  "https://example.com/owner/repo.git",
+			expectedAuth: &http.BasicAuth{Username: "alice", Password: "secret"},
+		},
+		{
+			name: "credential helper",
+			host: "example.com",
+			credentials: map[string]templit.Credential{
+				"example.com": {Method: templit.AuthCredentialHelper, Helper: "!f() { echo username=bob; echo password=hunter2; }; f"},
+			},
+			expectedURL:  "https://example.com/owner/repo.git",
+			expectedAuth: &http.BasicAuth{Username: "bob", Password: "hunter2"},
+		},
+		{
+			name: "ssh url",
+			host: "ssh://git@example.com",
+			credentials: map[string]templit.Credential{
+				"example.com": {Method: templit.AuthNone},
+			},
+			expectedURL: "ssh://git@example.com/owner/repo.git",
+		},
+		{
+			name: "owner prefix takes precedence over host",
+			host: "example.com",
+			credentials: map[string]templit.Credential{
+				"example.com":       {Method: templit.AuthToken, Token: "host"},
+				"example.com/owner": {Method: templit.AuthToken, Token: "owner"},
+				"example.com/other": {Method: templit.AuthToken, Token: "other"},
+			},
+			expectedURL:  "https://example.com/owner/repo.git",
+			expectedAuth: &http.BasicAuth{Username: "username", Password: "owner"},
+		},
+		{
+			name:  "rewrite to mirror uses mirror credentials",
+			host:  "example.com",
+			token: "leaked",
+			credentials: map[string]templit.Credential{
+				"example.com":       {Method: templit.AuthToken, Token: "public"},
+				"mirror.internal":   {Method: templit.AuthToken, Token: "mirror"},
+				"mirror.internal/x": {Method: templit.AuthToken, Token: "wrong"},
+			},
+			rewrites: []templit.Rewrite{
+				{URL: "other.internal/", InsteadOf: "example.com/"},
+				{URL: "mirror.internal/mirror/", InsteadOf: "example.com/owner/"},
+			},
+			expectedURL:  "https://mirror.internal/mirror/repo.git",
+			expectedAuth: &http.BasicAuth{Username: "username", Password: "mirror"},
+		},
+		{
+			name: "rewrite to ssh",
+			host: "example.com",
+			rewrites: []templit.Rewrite{
+				{URL: "ssh://git@mirror.internal/", InsteadOf: "example.com/"},
+			},
+			credentials: map[string]templit.Credential{
+				"mirror.internal": {Method: templit.AuthNone},
+			},
+			expectedURL: "ssh://git@mirror.internal/owner/repo.git",
+		},
+		{
+			name: "missing ssh key",
+			host: "example.com",
+			credentials: map[string]templit.Credential{
+				"example.com": {Method: templit.AuthSSHKey, KeyFile: filepath.Join(t.TempDir(), "id_missing")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := templit.NewDefaultGitClient("main", tt.token)
+			client.Credentials = tt.credentials
+			client.Rewrites = tt.rewrites
+
+			repoURL, auth, err := client.Endpoint(tt.host, "owner", "repo")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if repoURL != tt.expectedURL {
+				t.Errorf("expected url %s, got %s", tt.expectedURL, repoURL)
+			}
+
+			if diff := cmp.Diff(tt.expectedAuth, auth); diff != "" {
+				t.Errorf("auth mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
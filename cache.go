@@ -0,0 +1,321 @@
+package templit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+// Cache is a persistent on-disk cache of dependency checkouts.
+// Checkouts are stored by host, owner, repo and resolved commit so they can be
+// shared between executors and across runs. Branch refs are re-resolved once
+// they are older than TTL, while tags and commit hashes never expire.
+type Cache struct {
+	// Dir is the root directory of the cache.
+	Dir string
+	// TTL is how long a resolved branch ref is trusted before it is fetched again.
+	TTL time.Duration
+
+	mu       sync.Mutex
+	resolved map[string]string
+}
+
+// cacheRef records the commit a ref resolved to.
+type cacheRef struct {
+	Commit  string    `json:"commit"`
+	Branch  bool      `json:"branch"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// NewCache returns a new Cache rooted at dir. If dir is empty, DefaultCacheDir is used.
+func NewCache(dir string, ttl time.Duration) (*Cache, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Cache{
+		Dir: dir,
+		TTL: ttl,
+	}, nil
+}
+
+// DefaultCacheDir returns the default cache directory inside the user cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache dir: %w", err)
+	}
+	return filepath.Join(dir, "templit"), nil
+}
+
+// Fetch returns the directory of a checkout of host/owner/repo at ref.
+// The repository is cloned with client on a cache miss or when a cached branch ref has expired.
+// The returned directory is shared and must not be modified.
+func (c *Cache) Fetch(client GitClient, host, owner, repo, ref string) (string, error) {
+	rel := filepath.Join(cacheHost(host), owner, repo)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid cache key %s", rel)
+	}
+
+	if ref == "" {
+		ref = client.DefaultBranch()
+	}
+
+	repoDir := filepath.Join(c.Dir, rel)
+	refFile := filepath.Join(repoDir, "refs", url.PathEscape(ref)+".json")
+	key := rel + "@" + ref
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.resolved == nil {
+		c.resolved = map[string]string{}
+	}
+
+	// refs resolved by this process are reused regardless of the TTL
+	if commit, ok := c.resolved[key]; ok {
+		if dir := filepath.Join(repoDir, commit); isDir(dir) {
+			return dir, nil
+		}
+	}
+
+	if record, err := readCacheRef(refFile); err == nil {
+		dir := filepath.Join(repoDir, record.Commit)
+		if isDir(dir) && (!record.Branch || time.Since(record.Fetched) < c.TTL) {
+			c.resolved[key] = record.Commit
+			return dir, nil
+		}
+	}
+
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp(repoDir, ".clone_")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir) // Cleanup if the checkout was not moved into place
+
+	if err := client.Clone(host, owner, repo, ref, tempDir); err != nil {
+		return "", fmt.Errorf("failed to clone repo: %w", err)
+	}
+
+	commit, err := resolveCommit(tempDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve commit: %w", err)
+	}
+
+	dir := filepath.Join(repoDir, commit)
+	if !isDir(dir) {
+		// another process may have stored the same commit in the meantime
+		if err := os.Rename(tempDir, dir); err != nil && !isDir(dir) {
+			return "", fmt.Errorf("failed to store checkout: %w", err)
+		}
+	}
+
+	record := cacheRef{
+		Commit:  commit,
+		Branch:  isBranch(dir, ref),
+		Fetched: time.Now(),
+	}
+	if err := writeCacheRef(refFile, record); err != nil {
+		return "", err
+	}
+
+	c.resolved[key] = commit
+
+	return dir, nil
+}
+
+// cacheHost strips the scheme and user from host so all transports share the same cache entries.
+func cacheHost(host string) string {
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+
+	if _, rest, ok := strings.Cut(host, "@"); ok {
+		host = rest
+	}
+
+	return host
+}
+
+// readCacheRef reads a ref record from disk.
+func readCacheRef(path string) (cacheRef, error) {
+	var record cacheRef
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return record, err
+	}
+
+	if err := json.Unmarshal(content, &record); err != nil {
+		return record, err
+	}
+
+	if record.Commit == "" {
+		return record, errors.New("empty commit in cache ref")
+	}
+
+	return record, nil
+}
+
+// writeCacheRef atomically writes a ref record to disk.
+func writeCacheRef(path string, record cacheRef) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache ref: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".ref_")
+	if err != nil {
+		return fmt.Errorf("failed to write cache ref: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache ref: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache ref: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cache ref: %w", err)
+	}
+
+	return nil
+}
+
+// resolveCommit returns the commit checked out in dir.
+// Directories that are not git repositories are identified by the hash of their content.
+func resolveCommit(dir string) (string, error) {
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			return hashFS(os.DirFS(dir), ".")
+		}
+		return "", err
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return "", err
+	}
+
+	return head.Hash().String(), nil
+}
+
+// isBranch reports whether ref is a branch rather than a tag or commit hash in the repository at path.
+// Refs are assumed to be branches when this cannot be determined.
+func isBranch(path, ref string) bool {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return true
+	}
+
+	if _, err := r.Tag(ref); err == nil {
+		return false
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return true
+	}
+
+	return len(ref) < 7 || !strings.HasPrefix(head.Hash().String(), ref)
+}
+
+// hashFS returns the sha256 hash of the file or of all files in the directory name of fsys, ignoring .git directories.
+func hashFS(fsys fs.FS, name string) (string, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+
+	if !info.IsDir() {
+		if err := hashFile(h, fsys, name); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	var files []string
+	err = fs.WalkDir(fsys, name, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && d.Name() == ".git" {
+			return fs.SkipDir
+		}
+
+		if d.Type().IsRegular() {
+			files = append(files, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		rel := strings.TrimPrefix(strings.TrimPrefix(file, name), "/")
+		if name == "." {
+			rel = file
+		}
+
+		fmt.Fprintf(h, "%s\x00", rel)
+		if err := hashFile(h, fsys, file); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile writes the content of the file name of fsys to w.
+func hashFile(w io.Writer, fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// isDir reports whether path is an existing directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,109 @@
+package templit_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/euforic/templit"
+)
+
+// countingGitClient is a MockGitClient that counts clones.
+type countingGitClient struct {
+	MockGitClient
+	clones int
+}
+
+// Clone clones a repository to the given destination and counts the call.
+func (c *countingGitClient) Clone(host, owner, repo, ref, dest string) error {
+	c.clones++
+	return c.MockGitClient.Clone(host, owner, repo, ref, dest)
+}
+
+// TestCache_Fetch tests the Fetch function.
+func TestCache_Fetch(t *testing.T) {
+	tests := []struct {
+		name           string
+		ttl            time.Duration
+		sameCache      bool
+		expectedClones int
+	}{
+		{
+			name:           "same cache reuses resolved ref",
+			ttl:            0,
+			sameCache:      true,
+			expectedClones: 1,
+		},
+		{
+			name:           "new cache within ttl",
+			ttl:            time.Hour,
+			expectedClones: 1,
+		},
+		{
+			name:           "new cache with expired branch",
+			ttl:            0,
+			expectedClones: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			client := &countingGitClient{}
+
+			cache, err := templit.NewCache(dir, tt.ttl)
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+
+			first, err := cache.Fetch(client, "test_data", "templates", "basic_test", "main")
+			if err != nil {
+				t.Fatalf("failed to fetch: %v", err)
+			}
+
+			if !tt.sameCache {
+				if cache, err = templit.NewCache(dir, tt.ttl); err != nil {
+					t.Fatalf("failed to create cache: %v", err)
+				}
+			}
+
+			second, err := cache.Fetch(client, "test_data", "templates", "basic_test", "main")
+			if err != nil {
+				t.Fatalf("failed to fetch: %v", err)
+			}
+
+			if first != second {
+				t.Errorf("expected same checkout, got %s and %s", first, second)
+			}
+
+			if _, err := os.Stat(second + "/greeting.txt"); err != nil {
+				t.Errorf("expected checkout to contain greeting.txt: %v", err)
+			}
+
+			if client.clones != tt.expectedClones {
+				t.Errorf("expected %d clones, got %d", tt.expectedClones, client.clones)
+			}
+		})
+	}
+}
+
+// TestEmbedFunc_Cache tests that EmbedFunc clones a repository once when a cache is used.
+func TestEmbedFunc_Cache(t *testing.T) {
+	client := &countingGitClient{}
+
+	cache, err := templit.NewCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	executor := templit.NewExecutor(client, templit.WithCache(cache))
+	for i := 0; i < 3; i++ {
+		if _, err := executor.EmbedFunc("https://test_data/templates/basic_test/greeting.txt@main", map[string]string{"Name": "John"}); err != nil {
+			t.Fatalf("failed to embed: %v", err)
+		}
+	}
+
+	if client.clones != 1 {
+		t.Errorf("expected 1 clone, got %d", client.clones)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/euforic/templit"
+	"golang.org/x/term"
+)
+
+// fillVariables asks on the terminal for the variables missing from values and adds the answers to values.
+// With the non_interactive flag it fails listing every missing variable without a default instead, including
+// those inferred from the templates. Without a terminal on stdin or once stdin is exhausted, missing variables are
+// left to their defaults and it only fails for those the spec declares as required without a default.
+func fillVariables(variables []templit.Variable, values map[string]interface{}) error {
+	interactive := !flagValues.nonInteractive && isTerminal(os.Stdin)
+
+	var missing []string
+	for _, v := range variables {
+		if _, ok := values[v.Name]; ok {
+			continue
+		}
+
+		// variables whose condition cannot be evaluated yet are asked for
+		if visible, err := v.Visible(values); err == nil && !visible {
+			continue
+		}
+
+		if interactive {
+			value, err := promptVariable(v, values)
+			if err == nil {
+				values[v.Name] = value
+				continue
+			}
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			// there are no more answers, the remaining variables are handled like without a terminal
+			fmt.Fprintln(os.Stderr)
+			interactive = false
+		}
+
+		if v.Default == nil && (v.Required || flagValues.nonInteractive) {
+			missing = append(missing, v.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing values for %s, pass them with --set, a values file or the JSON data", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// isTerminal reports whether f is a terminal. Other character devices, like /dev/null, are not.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// promptVariable asks for the value of v until the answer is valid. An empty answer selects the default.
+func promptVariable(v templit.Variable, values map[string]interface{}) (interface{}, error) {
+	def, err := v.DefaultValue(values)
+	if err != nil {
+		return nil, err
+	}
+
+	label := v.Name
+	if v.Description != "" {
+		label += " (" + v.Description + ")"
+	}
+
+	for {
+		switch {
+		case len(v.Choices) > 0:
+			fmt.Fprintf(os.Stderr, "%s:\n", label)
+			for i, choice := range v.Choices {
+				fmt.Fprintf(os.Stderr, "  %d) %v\n", i+1, choice)
+			}
+			fmt.Fprint(os.Stderr, "Choose")
+		case v.Type == templit.VariableBool:
+			fmt.Fprintf(os.Stderr, "%s [y/N]", label)
+		case v.Type == templit.VariableList:
+			fmt.Fprintf(os.Stderr, "%s, comma separated", label)
+		case v.Type == templit.VariableMap:
+			fmt.Fprintf(os.Stderr, "%s as a JSON object", label)
+		default:
+			fmt.Fprint(os.Stderr, label)
+			if v.Type != "" && v.Type != templit.VariableString {
+				fmt.Fprintf(os.Stderr, " <%s>", v.Type)
+			}
+		}
+		if def != nil {
+			fmt.Fprintf(os.Stderr, " [%v]", def)
+		}
+		fmt.Fprint(os.Stderr, ": ")
+
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("failed to read value of %s: %w", v.Name, err)
+		}
+
+		var answer interface{} = strings.TrimSpace(line)
+		switch {
+		case answer == "" && def != nil:
+			answer = def
+		case len(v.Choices) > 0:
+			if i, err := strconv.Atoi(answer.(string)); err == nil && i >= 1 && i <= len(v.Choices) {
+				answer = v.Choices[i-1]
+			}
+		case v.Type == templit.VariableBool:
+			switch strings.ToLower(answer.(string)) {
+			case "y", "yes":
+				answer = true
+			case "", "n", "no":
+				answer = false
+			}
+		case v.Type == templit.VariableMap:
+			object := map[string]interface{}{}
+			if text := answer.(string); text != "" {
+				if err := json.Unmarshal([]byte(text), &object); err != nil {
+					fmt.Fprintf(os.Stderr, "invalid JSON object: %s\n", err)
+					continue
+				}
+			}
+			answer = object
+		}
+
+		value, err := v.Validate(answer)
+		if err == nil {
+			return value, nil
+		}
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
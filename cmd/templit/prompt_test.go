@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestFillVariables tests filling missing variables without asking for them.
+func TestFillVariables(t *testing.T) {
+	variables := []templit.Variable{
+		{Name: "Name"},
+		{Name: "Owner", Required: true},
+		{Name: "Port", Required: true, Default: 8080},
+		{Name: "Debug", Type: templit.VariableBool},
+		{Name: "Region", When: "{{.Cloud}}"},
+	}
+
+	tests := []struct {
+		name           string
+		nonInteractive bool
+		expectedError  string
+	}{
+		{
+			name:          "without a terminal",
+			expectedError: "missing values for Owner, pass them with --set, a values file or the JSON data",
+		},
+		{
+			name:           "non-interactive",
+			nonInteractive: true,
+			expectedError:  "missing values for Owner, Debug, pass them with --set, a values file or the JSON data",
+		},
+	}
+
+	// stdin is not a terminal
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+
+	stdin := os.Stdin
+	os.Stdin = devNull
+	defer func() { os.Stdin = stdin }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagValues.nonInteractive = tt.nonInteractive
+			defer func() { flagValues.nonInteractive = false }()
+
+			values := map[string]interface{}{"Name": "api", "Cloud": false}
+			err := fillVariables(variables, values)
+			if err == nil || err.Error() != tt.expectedError {
+				t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+			}
+
+			if diff := cmp.Diff(map[string]interface{}{"Name": "api", "Cloud": false}, values); diff != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
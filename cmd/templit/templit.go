@@ -1,11 +1,17 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"maps"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/euforic/templit"
 	"github.com/spf13/cobra"
@@ -13,9 +19,32 @@ import (
 
 // flagValues stores the values of command-line flags
 var flagValues = struct {
-	token  string
-	branch string
-	remote string
+	token          string
+	tokenHost      string
+	gitConfig      string
+	branch         string
+	remote         string
+	cacheDir       string
+	cacheTTL       time.Duration
+	noCache        bool
+	lockfile       string
+	update         bool
+	dryRun         bool
+	planFormat     string
+	conflict       string
+	conflictRules  []string
+	manifest       bool
+	prune          string
+	nonInteractive bool
+	copyOnly       []string
+	leftDelim      string
+	rightDelim     string
+	values         []string
+	set            []string
+	envNamespace   string
+	schema         string
+	strict         bool
+	reportUnused   bool
 }{}
 
 // templitCmd represents the templit command
@@ -32,89 +61,572 @@ var templitCmd = &cobra.Command{
 
 // renderCmd represents the render command
 var renderCmd = &cobra.Command{
-	Use:   "render <inputPath> <outputPath> <jsonData>",
+	Use:   "render <inputPath> <outputPath> [jsonData]",
 	Short: "A CLI tool for rendering templates from remote repositories",
 	Long:  `generate is for rendering templates.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Check for the correct number of command-line arguments
-		if len(args) < 3 {
+		if len(args) < 2 {
 			if err := cmd.Help(); err != nil {
 				fmt.Fprintln(os.Stderr, err)
 			}
 			return
 		}
 
-		if flagValues.token == "" {
-			flagValues.token = os.Getenv("GIT_TOKEN")
+		// Extract the command-line arguments, missing values are asked for
+		inputPath, outputPath, inputData := args[0], args[1], "{}"
+		if len(args) > 2 {
+			inputData = args[2]
 		}
 
-		// Extract the command-line arguments
-		inputPath, outputPath, inputData := args[0], args[1], args[2]
+		// plan records the generated files instead of writing them in a dry run
+		var plan *templit.Plan
+		var out templit.Output
+		if flagValues.dryRun {
+			plan = templit.NewPlan(outputPath)
+			out = plan
+		}
 
-		// values stores the JSON data
-		var values map[string]interface{}
+		conflictOpts, err := conflictOptions()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
 
-		// Parse the JSON data from the command-line argument
-		if err := json.Unmarshal([]byte(inputData), &values); err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing JSON data: %s\n", err)
+		pruneMode, err := templit.ParsePruneMode(flagValues.prune)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing prune mode: %s\n", err)
 			return
 		}
 
-		// executor is the template executor
-		executor := templit.NewExecutor(templit.NewDefaultGitClient(flagValues.branch, flagValues.token))
+		// pruning the next render needs the manifest of this one
+		if pruneMode != templit.PruneOff {
+			flagValues.manifest = true
+		}
 
-		// funcMap defines the custom functions that can be used in templates
-		var funcMap = template.FuncMap{
-			// return an error for the embed and import functions if no GitHub token is provided
-			"embed": func(repoAndPath string, ctx interface{}) (string, error) {
-				return "", fmt.Errorf("embed function requires a GitHub token")
-			},
-			"import": func(repoAndPath string, destPath string, ctx interface{}) (string, error) {
-				return "", fmt.Errorf("import function requires a GitHub token")
-			},
+		// a dry run lists removed files in the plan
+		var report templit.StaleFunc
+		if plan == nil {
+			report = printStale
 		}
 
-		if flagValues.token != "" {
-			funcMap["embed"] = executor.EmbedFunc
-			funcMap["import"] = executor.ImportFunc(outputPath)
+		lock, err := generate(inputPath, outputPath, inputData, out, append(conflictOpts, templit.WithPrune(pruneMode, report))...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
 		}
 
-		// Copy the default function map from the templit package
-		maps.Copy(funcMap, templit.DefaultFuncMap)
-		executor.Funcs(funcMap)
+		if plan == nil {
+			saveLockfile(lock)
+			return
+		}
 
-		// If a remote repository is specified, process the template and write it to the output directory
-		if flagValues.remote != "" {
-			importParts, err := templit.ParseDepURL(flagValues.remote)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error parsing remote: %s\n", err)
-				return
+		if err := printPlan(plan); err != nil {
+			fmt.Fprintf(os.Stderr, "Error printing plan: %s\n", err)
+		}
+	},
+}
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <inputPath> <outputPath> [jsonData]",
+	Short: "Show how the output directory differs from the rendered templates",
+	Long: `diff renders the templates in memory and prints unified diffs against the output directory.
+Unmodified files that the ` + templit.ManifestName + ` manifest of the output directory records but the templates no longer generate
+are shown as removed. It exits with status 1 if the output directory differs and with status 2 on errors.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check for the correct number of command-line arguments
+		if len(args) < 2 {
+			if err := cmd.Help(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
 			}
+			os.Exit(2)
+		}
+
+		// Extract the command-line arguments, missing values are asked for
+		inputPath, outputPath, inputData := args[0], args[1], "{}"
+		if len(args) > 2 {
+			inputData = args[2]
+		}
+
+		// removed files are detected with the manifest of the previous render
+		if _, err := os.Stat(filepath.Join(outputPath, templit.ManifestName)); errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "No %s manifest in %s, files the templates no longer generate are not shown. Render with --manifest to record one.\n", templit.ManifestName, outputPath)
+		}
+
+		plan := templit.NewPlan(outputPath)
+		if _, err := generate(inputPath, outputPath, inputData, plan, templit.WithPrune(templit.PruneRemove, nil)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(2)
+		}
+
+		if err := plan.WriteDiff(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing diff: %s\n", err)
+			os.Exit(2)
+		}
+
+		if plan.Drift() {
+			os.Exit(1)
+		}
+	},
+}
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update <outputPath> <ref> [jsonData]",
+	Short: "Update a generated project to a new version of its template",
+	Long: `update re-renders the template recorded in the project's ` + templit.ManifestName + ` at its recorded ref and at <ref>,
+and merges the changes between both into the project with a three-way merge, keeping local modifications.
+The values files, jsonData and --set values are merged over the recorded data. Overlapping changes are marked with conflict markers and make
+update exit with status 1.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Check for the correct number of command-line arguments
+		if len(args) < 2 {
+			if err := cmd.Help(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			return
+		}
+
+		outputPath, ref := args[0], args[1]
+		manifestPath := filepath.Join(outputPath, templit.ManifestName)
+
+		manifest, err := templit.LoadManifest(manifestPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Error loading manifest: %s has no %s manifest, render it with --manifest to update it later\n", outputPath, templit.ManifestName)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading manifest: %s\n", err)
+			return
+		}
+
+		inputData := ""
+		if len(args) > 2 {
+			inputData = args[2]
+		}
 
-			importParts.Path = inputPath
+		// values stores the data of the new version
+		values, err := loadValues(maps.Clone(manifest.Data), inputData)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		executor, lock, err := newExecutor(outputPath, templit.WithManifest(manifest))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return
+		}
+
+		result, err := executor.Update(manifest.Source, ref, outputPath, withEnv(manifest.Data), withEnv(values))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating project: %s\n", err)
+			return
+		}
 
-			if _, err := executor.ImportFunc(outputPath)(importParts.String(), "./", values); err != nil {
-				fmt.Fprintf(os.Stderr, "Error processing template: %s\n", err)
+		for _, group := range []struct {
+			label string
+			files []string
+		}{
+			{"added", result.Added},
+			{"updated", result.Updated},
+			{"conflict", result.Conflicts},
+			{"removed", result.Removed},
+			{"skipped", result.Skipped},
+		} {
+			for _, file := range group.files {
+				fmt.Printf("%-9s %s\n", group.label, file)
 			}
+		}
+
+		manifest.Data = values
+		manifest.Version = templit.Version
+		if err := manifest.Save(manifestPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving manifest: %s\n", err)
 			return
 		}
 
-		// Copy the default function map from the templit package
-		maps.Copy(funcMap, templit.DefaultFuncMap)
-		executor.Funcs(funcMap)
+		saveLockfile(lock)
 
+		if len(result.Conflicts) > 0 {
+			fmt.Fprintf(os.Stderr, "%d files have conflicts, resolve the conflict markers before committing\n", len(result.Conflicts))
+			os.Exit(1)
+		}
+	},
+}
+
+// generate renders the templates at inputPath, or at the remote flag, into outputPath.
+// Files are written to out, or to disk if out is nil. It returns the lockfile of the resolved dependencies.
+func generate(inputPath, outputPath, inputData string, out templit.Output, extraOpts ...templit.ExecutorOption) (*templit.Lockfile, error) {
+	// values stores the data of the values files, the JSON data and the set flags
+	values, err := loadValues(nil, inputData)
+	if err != nil {
+		return nil, err
+	}
+	values = withEnv(values)
+
+	if out != nil {
+		extraOpts = append(extraOpts, templit.WithOutput(out))
+	}
+
+	// source is the template the output is generated from
+	var source *templit.DepInfo
+	if flagValues.remote != "" {
+		if source, err = templit.ParseDepURL(flagValues.remote); err != nil {
+			return nil, fmt.Errorf("failed to parse remote: %w", err)
+		}
+
+		source.Path = inputPath
+		if source.Tag == "" && !source.IsLocal() {
+			source.Tag = flagValues.branch
+		}
+	} else {
+		absPath, err := filepath.Abs(inputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve input path: %w", err)
+		}
+		source = &templit.DepInfo{Scheme: "file", Repo: filepath.ToSlash(absPath)}
+	}
+
+	// the manifest records the template, data and generated files so the project can be updated later
+	manifest := templit.NewManifest(source.String())
+	extraOpts = append(extraOpts, templit.WithManifest(manifest))
+
+	executor, lock, err := newExecutor(outputPath, extraOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// variables are the fields the templates use, values that were not passed are asked for
+	var variables []templit.Variable
+	if flagValues.remote != "" {
+		variables, err = executor.InferDepVariables(source.String())
+	} else {
+		variables, err = executor.InferVariables(os.DirFS(inputPath), ".")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template variables: %w", err)
+	}
+
+	if err := fillVariables(variables, values); err != nil {
+		return nil, err
+	}
+
+	// the environment is not recorded, it is exposed again on update
+	manifest.Data = maps.Clone(values)
+	if flagValues.envNamespace != "" {
+		delete(manifest.Data, flagValues.envNamespace)
+	}
+
+	if flagValues.remote != "" {
+		// If a remote repository is specified, process the template and write it to the output directory
+		if _, err := executor.ImportFunc(outputPath)(source.String(), "./", values); err != nil {
+			return nil, fmt.Errorf("failed to process template: %w", err)
+		}
+
+		if _, err := executor.PruneStale(outputPath); err != nil {
+			return nil, fmt.Errorf("failed to prune stale files: %w", err)
+		}
+	} else {
 		// Process the templates in the input directory and write them to the output directory
 		if err := executor.WalkAndProcessDir(inputPath, outputPath, values); err != nil {
-			fmt.Fprintf(os.Stderr, "Error processing template: %s\n", err)
+			return nil, fmt.Errorf("failed to process template: %w", err)
 		}
-	},
+	}
+
+	if flagValues.reportUnused {
+		for _, name := range executor.UnusedVariables(manifest.Data) {
+			fmt.Fprintf(os.Stderr, "unused variable %s\n", name)
+		}
+	}
+
+	if out == nil && flagValues.manifest {
+		if err := manifest.Save(filepath.Join(outputPath, templit.ManifestName)); err != nil {
+			return nil, fmt.Errorf("failed to save manifest: %w", err)
+		}
+	}
+
+	return lock, nil
+}
+
+// loadValues merges the values files, the JSON data, which may be empty, and the set flags over base, in that order.
+// base may be nil.
+func loadValues(base map[string]interface{}, inputData string) (map[string]interface{}, error) {
+	values := base
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	for _, path := range flagValues.values {
+		fileValues, err := templit.LoadValues(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load values: %w", err)
+		}
+		values = templit.MergeValues(values, fileValues)
+	}
+
+	if inputData != "" {
+		var jsonValues map[string]interface{}
+		if err := json.Unmarshal([]byte(inputData), &jsonValues); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON data: %w", err)
+		}
+		values = templit.MergeValues(values, jsonValues)
+	}
+
+	for _, assignment := range flagValues.set {
+		if err := templit.SetValue(values, assignment); err != nil {
+			return nil, fmt.Errorf("failed to parse set value: %w", err)
+		}
+	}
+
+	return values, nil
+}
+
+// withEnv returns values with the environment variables below the env_namespace flag, if it is set.
+// values take precedence over the environment.
+func withEnv(values map[string]interface{}) map[string]interface{} {
+	if flagValues.envNamespace == "" {
+		return values
+	}
+
+	env := map[string]interface{}{flagValues.envNamespace: templit.EnvValues(os.Environ())}
+	return templit.MergeValues(env, values)
+}
+
+// newExecutor creates the template executor for outputPath from the command-line flags.
+// It returns the executor and the lockfile pinning its dependencies.
+func newExecutor(outputPath string, extraOpts ...templit.ExecutorOption) (*templit.Executor, *templit.Lockfile, error) {
+	if flagValues.token == "" {
+		flagValues.token = os.Getenv("GIT_TOKEN")
+	}
+
+	// opts configures the template executor
+	var opts []templit.ExecutorOption
+
+	if !flagValues.noCache {
+		cache, err := templit.NewCache(flagValues.cacheDir, flagValues.cacheTTL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open cache: %w", err)
+		}
+		opts = append(opts, templit.WithCache(cache))
+	}
+
+	// lock pins embed and import references to resolved commits
+	lock := &templit.Lockfile{}
+	if !flagValues.update {
+		var err error
+		if lock, err = templit.LoadLockfile(flagValues.lockfile); err != nil {
+			return nil, nil, fmt.Errorf("failed to load lockfile: %w", err)
+		}
+	}
+	opts = append(opts, templit.WithLockfile(lock, flagValues.update))
+	opts = append(opts, templit.WithCopyOnly(flagValues.copyOnly...))
+	opts = append(opts, templit.WithDelims(flagValues.leftDelim, flagValues.rightDelim))
+	if flagValues.schema != "" {
+		opts = append(opts, templit.WithSchema(flagValues.schema))
+	}
+	if flagValues.strict {
+		opts = append(opts, templit.WithStrict())
+	}
+	opts = append(opts, extraOpts...)
+
+	gitClient, err := newGitClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure git: %w", err)
+	}
+
+	// executor is the template executor
+	executor := templit.NewExecutor(gitClient, opts...)
+
+	// funcMap defines the custom functions that can be used in templates
+	// repositories without a token are cloned anonymously or over ssh
+	var funcMap = template.FuncMap{
+		"embed":  executor.EmbedFunc,
+		"import": executor.ImportFunc(outputPath),
+	}
+
+	// Copy the default function map from the templit package
+	maps.Copy(funcMap, templit.DefaultFuncMap)
+	executor.Funcs(funcMap)
+
+	return executor, lock, nil
+}
+
+// printStale prints a stale file found while pruning
+func printStale(path string, removed bool) {
+	label := "stale"
+	if removed {
+		label = "removed"
+	}
+
+	fmt.Printf("%-9s %s\n", label, path)
+}
+
+// conflictOptions returns the executor options for the conflict and conflict_rule flags
+func conflictOptions() ([]templit.ExecutorOption, error) {
+	policy, err := templit.ParseConflictPolicy(flagValues.conflict)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse conflict policy: %w", err)
+	}
+
+	var rules []templit.ConflictRule
+	for _, rule := range flagValues.conflictRules {
+		glob, name, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid conflict rule %q, expected <glob>=<policy>", rule)
+		}
+
+		rulePolicy, err := templit.ParseConflictPolicy(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse conflict rule %q: %w", rule, err)
+		}
+
+		rules = append(rules, templit.ConflictRule{Glob: glob, Policy: rulePolicy})
+	}
+
+	return []templit.ExecutorOption{
+		templit.WithConflictPolicy(policy, rules...),
+		templit.WithConflictPrompt(promptConflict),
+	}, nil
+}
+
+// stdin reads the answers to interactive prompts
+var stdin = bufio.NewReader(os.Stdin)
+
+// promptConflict asks on the terminal what to do with an existing file that differs from the generated one
+func promptConflict(path string, existing, generated []byte) (templit.ConflictPolicy, error) {
+	if !isTerminal(os.Stdin) {
+		return "", fmt.Errorf("%s: %w and stdin is not a terminal to ask what to do, pick another --conflict policy", path, templit.ErrConflict)
+	}
+
+	for {
+		fmt.Fprintf(os.Stderr, "%s already exists and differs. [o]verwrite, [s]kip, write [n]ew file, show [d]iff, [f]ail? ", path)
+
+		answer, err := stdin.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "o", "overwrite":
+			return templit.ConflictOverwrite, nil
+		case "s", "skip":
+			return templit.ConflictSkip, nil
+		case "n", "new":
+			return templit.ConflictNew, nil
+		case "f", "fail":
+			return templit.ConflictFail, nil
+		case "d", "diff":
+			fmt.Fprint(os.Stderr, templit.UnifiedDiff("a/"+path, "b/"+path, string(existing), string(generated)))
+		}
+	}
+}
+
+// newGitClient creates the git client from the git config file and token flags.
+// The token is only sent to the token host so it is never leaked to other hosts.
+func newGitClient() (*templit.DefaultGitClient, error) {
+	client := templit.NewDefaultGitClient(flagValues.branch, "")
+
+	configPath := flagValues.gitConfig
+	if configPath == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			if _, err := os.Stat(filepath.Join(dir, "templit", "git.json")); err == nil {
+				configPath = filepath.Join(dir, "templit", "git.json")
+			}
+		}
+	}
+
+	if configPath != "" {
+		cfg, err := templit.LoadGitConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+		client.Configure(cfg)
+	}
+
+	if flagValues.token != "" {
+		client.Configure(&templit.GitConfig{
+			Credentials: map[string]templit.Credential{
+				flagValues.tokenHost: {Method: templit.AuthToken, Token: flagValues.token},
+			},
+		})
+	}
+
+	return client, nil
+}
+
+// printPlan writes the plan to stdout in the format selected by the plan_format flag
+func printPlan(plan *templit.Plan) error {
+	entries := plan.Sorted()
+
+	switch flagValues.planFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case "text", "":
+		for _, entry := range entries {
+			path := entry.Path
+			if entry.Dir {
+				path += string(filepath.Separator)
+			}
+
+			if entry.Source != "" {
+				fmt.Printf("%-9s %s %s (from %s)\n", entry.Status, entry.Mode, path, entry.Source)
+				continue
+			}
+			fmt.Printf("%-9s %s %s\n", entry.Status, entry.Mode, path)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown plan format %q", flagValues.planFormat)
+	}
+}
+
+// saveLockfile writes the lockfile if new dependencies were resolved
+func saveLockfile(lock *templit.Lockfile) {
+	if !lock.Changed() {
+		return
+	}
+
+	if err := lock.Save(flagValues.lockfile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error saving lockfile: %s\n", err)
+	}
 }
 
 func init() {
-	templitCmd.AddCommand(renderCmd)
-	renderCmd.Flags().StringVarP(&flagValues.token, "git_token", "t", "", "GitHub token")
-	renderCmd.Flags().StringVarP(&flagValues.branch, "branch", "b", "main", "GitHub branch")
-	renderCmd.Flags().StringVarP(&flagValues.remote, "remote", "r", "", "remote repository to use. (example: github.com/owner/repo@ref)")
+	templitCmd.AddCommand(renderCmd, diffCmd, updateCmd)
+	for _, cmd := range []*cobra.Command{renderCmd, diffCmd, updateCmd} {
+		cmd.Flags().StringVarP(&flagValues.token, "git_token", "t", "", "GitHub token")
+		cmd.Flags().StringVar(&flagValues.tokenHost, "git_token_host", "github.com", "host, optionally followed by an owner prefix, the git token is sent to")
+		cmd.Flags().StringVar(&flagValues.gitConfig, "git_config", "", "JSON file with per host credentials and URL rewrites (default is templit/git.json in the user config directory)")
+		cmd.Flags().StringVarP(&flagValues.branch, "branch", "b", "main", "GitHub branch")
+		cmd.Flags().StringVarP(&flagValues.remote, "remote", "r", "", "remote repository to use. (example: github.com/owner/repo@ref)")
+		cmd.Flags().StringVar(&flagValues.cacheDir, "cache_dir", "", "directory for cached repository checkouts (default is the user cache directory)")
+		cmd.Flags().DurationVar(&flagValues.cacheTTL, "cache_ttl", 10*time.Minute, "how long cached branch refs are used before fetching again")
+		cmd.Flags().BoolVar(&flagValues.noCache, "no_cache", false, "clone repositories on every use instead of caching them")
+		cmd.Flags().StringVar(&flagValues.lockfile, "lockfile", templit.LockfileName, "lockfile pinning embed and import references to commits")
+		cmd.Flags().BoolVar(&flagValues.update, "update", false, "resolve embed and import references from their live refs and update the lockfile")
+		cmd.Flags().StringVar(&flagValues.leftDelim, "left_delim", "{{", "left action delimiter of templates and file names")
+		cmd.Flags().StringVar(&flagValues.rightDelim, "right_delim", "}}", "right action delimiter of templates and file names")
+		cmd.Flags().StringArrayVarP(&flagValues.values, "values", "f", nil, "YAML, TOML, JSON or .env file with render data, merged over the previous files (repeatable)")
+		cmd.Flags().StringArrayVar(&flagValues.set, "set", nil, "render data value as <key>=<value>, nested keys are separated by dots, true, false, null and integers without a leading zero are typed and other values are strings (repeatable)")
+		cmd.Flags().StringVar(&flagValues.envNamespace, "env_namespace", "", "expose the environment variables as a map under this top-level data field, like Env")
+		cmd.Flags().StringArrayVar(&flagValues.copyOnly, "copy_only", nil, "glob of template files copied verbatim instead of rendered, like **/*.png or charts/** (repeatable)")
+		cmd.Flags().StringVar(&flagValues.schema, "schema", "", "JSON Schema file the render data is validated against before any file is generated, in addition to the template's "+templit.SchemaFileName)
+		cmd.Flags().BoolVar(&flagValues.strict, "strict", false, "fail on template keys missing from the render data instead of rendering <no value>")
+	}
+	for _, cmd := range []*cobra.Command{renderCmd, diffCmd} {
+		cmd.Flags().BoolVar(&flagValues.reportUnused, "report_unused", false, "report render data variables that no template references")
+		cmd.Flags().BoolVar(&flagValues.nonInteractive, "non_interactive", false, "never ask for template variables missing from the render data, like when stdin is not a terminal")
+	}
+	renderCmd.Flags().BoolVar(&flagValues.dryRun, "dry_run", false, "print the files that would be generated and how they compare to the output directory without writing them")
+	renderCmd.Flags().StringVar(&flagValues.planFormat, "plan_format", "text", "format of the dry run plan (text or json)")
+	renderCmd.Flags().StringVar(&flagValues.conflict, "conflict", string(templit.ConflictOverwrite), "what to do when a file already exists with different content (overwrite, skip, fail, new or prompt)")
+	renderCmd.Flags().BoolVar(&flagValues.manifest, "manifest", false, "write a "+templit.ManifestName+" manifest recording the template, data and generated files into the output directory, which update needs and --prune implies")
+	renderCmd.Flags().StringVar(&flagValues.prune, "prune", string(templit.PruneOff), "what to do with files generated by the previous render that are no longer generated (off, report or remove)")
+	renderCmd.Flags().StringArrayVar(&flagValues.conflictRules, "conflict_rule", nil, "conflict policy for files matching a glob as <glob>=<policy>, the first matching rule wins (repeatable)")
 }
 
 // main is the entrypoint of the application
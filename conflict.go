@@ -0,0 +1,200 @@
+package templit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrConflict is returned when a generated file would replace an existing file with different content
+// and the conflict policy of the file is ConflictFail.
+var ErrConflict = errors.New("file already exists")
+
+// ConflictPolicy decides what happens when a generated file would replace an existing file with different content.
+type ConflictPolicy string
+
+const (
+	// ConflictOverwrite replaces the existing file. It is the default policy.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictSkip keeps the existing file.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictFail stops generation with ErrConflict.
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictNew keeps the existing file and writes the generated file next to it with a ".new" suffix.
+	ConflictNew ConflictPolicy = "new"
+	// ConflictPrompt asks the ConflictPrompt of the executor which policy to apply.
+	ConflictPrompt ConflictPolicy = "prompt"
+)
+
+// ConflictRule applies Policy to the files matching Glob.
+// Globs without a slash match the file name, others match the path relative to the output directory,
+// where "**" matches any number of directories.
+type ConflictRule struct {
+	Glob   string
+	Policy ConflictPolicy
+}
+
+// ConflictPromptFunc asks which policy to apply to the file at path, given its existing and generated content.
+// It must not return ConflictPrompt.
+type ConflictPromptFunc func(path string, existing, generated []byte) (ConflictPolicy, error)
+
+// ParseConflictPolicy parses the name of a conflict policy.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(name); policy {
+	case ConflictOverwrite, ConflictSkip, ConflictFail, ConflictNew, ConflictPrompt:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q", name)
+	}
+}
+
+// WithConflictPolicy sets the policy for files that already exist with different content.
+// The first rule matching a file takes precedence over policy.
+func WithConflictPolicy(policy ConflictPolicy, rules ...ConflictRule) ExecutorOption {
+	return func(e *Executor) {
+		e.conflict = policy
+		e.conflictRules = rules
+	}
+}
+
+// WithConflictPrompt sets the function asked for files with the ConflictPrompt policy.
+func WithConflictPrompt(prompt ConflictPromptFunc) ExecutorOption {
+	return func(e *Executor) {
+		e.conflictPrompt = prompt
+	}
+}
+
+// conflictPolicy returns the conflict policy of the file at rel, which is relative to the output directory.
+// The conflict rules take precedence over the policy of the file, which takes precedence over the executor's policy.
+func (e *Executor) conflictPolicy(rel string, filePolicy ConflictPolicy) ConflictPolicy {
+	for _, rule := range e.conflictRules {
+		if matchGlob(rule.Glob, rel) {
+			return rule.Policy
+		}
+	}
+
+	if filePolicy != "" {
+		return filePolicy
+	}
+
+	if e.conflict == "" {
+		return ConflictOverwrite
+	}
+
+	return e.conflict
+}
+
+// writeFile writes a generated file to the output and records the written file in the manifest.
+// Conflicts with an existing file are resolved by the conflict policy of the file's path relative to root,
+// or by filePolicy, the file's own policy, if it is not empty and no conflict rule matches.
+// Files whose existing content is kept, because they are skipped or the generated file is written next to them,
+// are recorded with their existing content, so they are not stale for the next run.
+func (e *Executor) writeFile(root, filePath string, data []byte, perm fs.FileMode, filePolicy ConflictPolicy) error {
+	write := func(name string) error {
+		if err := e.out.WriteFile(name, data, perm); err != nil {
+			return err
+		}
+
+		e.recordFile(root, name, data)
+		return nil
+	}
+
+	reader, ok := e.out.(OutputReader)
+	if !ok {
+		return write(filePath)
+	}
+
+	existing, err := reader.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && bytes.Equal(existing, data)) {
+		return write(filePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil {
+		rel = filePath
+	}
+	rel = filepath.ToSlash(rel)
+
+	policy := e.conflictPolicy(rel, filePolicy)
+
+	// dry runs report conflicts instead of resolving them
+	if out, ok := e.out.(conflictOutput); ok && policy != ConflictOverwrite {
+		out.conflict(filePath, data, perm)
+		e.recordFile(root, filePath, data)
+		return nil
+	}
+
+	if policy == ConflictPrompt {
+		if e.conflictPrompt == nil {
+			return fmt.Errorf("%s: %w and no conflict prompt is configured", rel, ErrConflict)
+		}
+
+		if policy, err = e.conflictPrompt(rel, existing, data); err != nil {
+			return err
+		}
+	}
+
+	switch policy {
+	case ConflictOverwrite:
+		return write(filePath)
+	case ConflictSkip:
+		e.recordFile(root, filePath, existing)
+		return nil
+	case ConflictFail:
+		return fmt.Errorf("%s: %w", rel, ErrConflict)
+	case ConflictNew:
+		e.recordFile(root, filePath, existing)
+		return write(filePath + ".new")
+	default:
+		return fmt.Errorf("%s: unknown conflict policy %q", rel, policy)
+	}
+}
+
+// conflictOutput is implemented by outputs that record conflicts with existing files instead of writing them.
+type conflictOutput interface {
+	conflict(path string, data []byte, perm fs.FileMode)
+}
+
+// matchGlob reports whether the slash separated path name matches pattern.
+// Patterns without a slash match the last element of name. "**" matches any number of path elements.
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchElems(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+// matchElems matches the path elements of name against the elements of pattern.
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -0,0 +1,154 @@
+package templit_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestConflictPolicy tests how generated files that conflict with existing files are written.
+func TestConflictPolicy(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go":         {Data: []byte("package {{.Name}}")},
+		"docs/README.md":  {Data: []byte("# {{.Name}}")},
+		"docs/CHANGES.md": {Data: []byte("unchanged")},
+	}
+
+	existing := map[string]string{
+		"out/main.go":         "package edited",
+		"out/docs/README.md":  "# edited",
+		"out/docs/CHANGES.md": "unchanged",
+	}
+
+	tests := []struct {
+		name          string
+		policy        templit.ConflictPolicy
+		rules         []templit.ConflictRule
+		prompt        templit.ConflictPromptFunc
+		expected      map[string]string
+		recorded      []string
+		expectedError error
+	}{
+		{
+			name: "overwrite by default",
+			expected: map[string]string{
+				"main.go":         "package app",
+				"docs/README.md":  "# app",
+				"docs/CHANGES.md": "unchanged",
+			},
+			recorded: []string{"docs/CHANGES.md", "docs/README.md", "main.go"},
+		},
+		{
+			name:   "skip",
+			policy: templit.ConflictSkip,
+			expected: map[string]string{
+				"main.go":         "package edited",
+				"docs/README.md":  "# edited",
+				"docs/CHANGES.md": "unchanged",
+			},
+			recorded: []string{"docs/CHANGES.md", "docs/README.md", "main.go"},
+		},
+		{
+			name:   "new",
+			policy: templit.ConflictNew,
+			expected: map[string]string{
+				"main.go":            "package edited",
+				"main.go.new":        "package app",
+				"docs/README.md":     "# edited",
+				"docs/README.md.new": "# app",
+				"docs/CHANGES.md":    "unchanged",
+			},
+			recorded: []string{"docs/CHANGES.md", "docs/README.md", "docs/README.md.new", "main.go", "main.go.new"},
+		},
+		{
+			name:          "fail",
+			policy:        templit.ConflictFail,
+			expectedError: templit.ErrConflict,
+		},
+		{
+			name:   "rules by glob",
+			policy: templit.ConflictFail,
+			rules: []templit.ConflictRule{
+				{Glob: "docs/**", Policy: templit.ConflictSkip},
+				{Glob: "*.go", Policy: templit.ConflictOverwrite},
+			},
+			expected: map[string]string{
+				"main.go":         "package app",
+				"docs/README.md":  "# edited",
+				"docs/CHANGES.md": "unchanged",
+			},
+			recorded: []string{"docs/CHANGES.md", "docs/README.md", "main.go"},
+		},
+		{
+			name:   "prompt",
+			policy: templit.ConflictPrompt,
+			prompt: func(path string, existing, generated []byte) (templit.ConflictPolicy, error) {
+				if path == "main.go" {
+					return templit.ConflictOverwrite, nil
+				}
+				return templit.ConflictSkip, nil
+			},
+			expected: map[string]string{
+				"main.go":         "package app",
+				"docs/README.md":  "# edited",
+				"docs/CHANGES.md": "unchanged",
+			},
+			recorded: []string{"docs/CHANGES.md", "docs/README.md", "main.go"},
+		},
+		{
+			name:          "prompt without prompt func",
+			policy:        templit.ConflictPrompt,
+			expectedError: templit.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := templit.NewMemOutput()
+			out.Root = "out"
+			for name, content := range existing {
+				if err := out.WriteFile(name, []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+
+			manifest := templit.NewManifest("app")
+			executor := templit.NewExecutor(nil,
+				templit.WithOutput(out),
+				templit.WithManifest(manifest),
+				templit.WithConflictPolicy(tt.policy, tt.rules...),
+				templit.WithConflictPrompt(tt.prompt),
+			)
+
+			err := executor.WalkAndProcessFS(fsys, ".", "out", map[string]string{"Name": "app"})
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to process fs: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, memFiles(out)); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+
+			// kept files are recorded with their existing content
+			var recorded []string
+			for name := range manifest.Files {
+				recorded = append(recorded, name)
+			}
+			sort.Strings(recorded)
+
+			if diff := cmp.Diff(tt.recorded, recorded); diff != "" {
+				t.Errorf("manifest mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,17 +1,29 @@
 package templit
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/go-git/go-billy/v5/osfs"
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 // DepInfo contains information about an embed URL.
 type DepInfo struct {
+	// Scheme is the transport used to clone the repository. It is empty for https.
+	Scheme string
+	// User is the user of an ssh URL.
+	User  string
 	Host  string
 	Owner string
 	Repo  string
@@ -24,15 +36,24 @@ type DepInfo struct {
 func (d DepInfo) String() string {
 	var builder strings.Builder
 
-	builder.WriteString(d.Host)
-	builder.WriteRune('/')
-	builder.WriteString(d.Owner)
-	builder.WriteRune('/')
-	builder.WriteString(d.Repo)
-
-	if d.Path != "" {
+	if d.IsLocal() {
+		builder.WriteString(d.Remote())
+		builder.WriteString(d.Repo)
+		if d.Path != "" {
+			builder.WriteString("//")
+			builder.WriteString(d.Path)
+		}
+	} else {
+		builder.WriteString(d.Remote())
+		builder.WriteRune('/')
+		builder.WriteString(d.Owner)
 		builder.WriteRune('/')
-		builder.WriteString(d.Path)
+		builder.WriteString(d.Repo)
+
+		if d.Path != "" {
+			builder.WriteRune('/')
+			builder.WriteString(d.Path)
+		}
 	}
 
 	if d.Block != "" {
@@ -48,9 +69,46 @@ func (d DepInfo) String() string {
 	return builder.String()
 }
 
+// Remote returns the host of the repository prefixed with the scheme and user for non https URLs.
+// This is the host passed to GitClient.Clone.
+func (d DepInfo) Remote() string {
+	if d.Scheme == "" {
+		return d.Host
+	}
+
+	if d.IsLocal() {
+		return "file://"
+	}
+
+	if d.User != "" {
+		return d.Scheme + "://" + d.User + "@" + d.Host
+	}
+
+	return d.Scheme + "://" + d.Host
+}
+
+// IsLocal reports whether the dependency is a directory on the local filesystem.
+// For local dependencies Repo is the root directory, which may be inside a git repository.
+func (d DepInfo) IsLocal() bool {
+	return d.Scheme == "file"
+}
+
+// scpURLPattern matches scp-like ssh URLs such as git@github.com:owner/repo.
+var scpURLPattern = regexp.MustCompile(`^([\w.-]+)@([\w.-]+):(.+)$`)
+
 // ParseDepURL is a parsed embed URL.
+// Besides http(s) URLs, ssh URLs in the form of ssh://git@host/owner/repo and git@host:owner/repo are supported.
+// Local directories are referenced with file:// URLs or paths starting with ./, ../ or /.
 func ParseDepURL(rawURL string) (*DepInfo, error) {
-	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+	if isLocalURL(rawURL) {
+		return parseLocalDepURL(rawURL)
+	}
+
+	if m := scpURLPattern.FindStringSubmatch(rawURL); m != nil && !strings.Contains(rawURL, "://") {
+		rawURL = "ssh://" + m[1] + "@" + m[2] + "/" + m[3]
+	}
+
+	if !strings.Contains(rawURL, "://") {
 		rawURL = "https://" + rawURL
 	}
 
@@ -59,6 +117,16 @@ func ParseDepURL(rawURL string) (*DepInfo, error) {
 		return nil, err
 	}
 
+	var scheme, user string
+	switch u.Scheme {
+	case "http", "https":
+	case "ssh":
+		scheme = u.Scheme
+		user = u.User.Username()
+	default:
+		return nil, fmt.Errorf("unsupported scheme %s in embed URL", u.Scheme)
+	}
+
 	// Extract the tag if it exists before splitting the path
 	fullPath := strings.Trim(u.Path, "/")
 	tag := ""
@@ -86,12 +154,63 @@ func ParseDepURL(rawURL string) (*DepInfo, error) {
 	}
 
 	return &DepInfo{
-		Host:  u.Host,
-		Owner: owner,
-		Repo:  repo,
-		Path:  path,
-		Block: block,
-		Tag:   tag,
+		Scheme: scheme,
+		User:   user,
+		Host:   u.Host,
+		Owner:  owner,
+		Repo:   repo,
+		Path:   path,
+		Block:  block,
+		Tag:    tag,
+	}, nil
+}
+
+// isLocalURL reports whether rawURL references the local filesystem.
+func isLocalURL(rawURL string) bool {
+	for _, prefix := range []string{"file://", "./", "../", "/"} {
+		if strings.HasPrefix(rawURL, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLocalDepURL parses a local dependency URL in the form of file://<root>//<path>#<block>@<ref>.
+// Without the // separator the last element of the path is the path inside the parent directory.
+func parseLocalDepURL(rawURL string) (*DepInfo, error) {
+	rawPath := strings.TrimPrefix(rawURL, "file://")
+
+	rawPath, fragment, _ := strings.Cut(rawPath, "#")
+	rawPath, tag := splitAtSign(rawPath)
+
+	block, fragmentTag := extractBlockAndTag(fragment)
+	if fragmentTag != "" {
+		tag = fragmentTag
+	}
+
+	if rawPath == "" {
+		return nil, fmt.Errorf("invalid path format in embed URL")
+	}
+
+	root, subPath, ok := strings.Cut(rawPath, "//")
+	if !ok {
+		root, subPath = path.Split(strings.TrimSuffix(rawPath, "/"))
+		if root == "" {
+			root = "."
+		}
+	}
+
+	root = path.Clean(root)
+	if subPath = path.Clean("/" + subPath)[1:]; subPath == "." {
+		subPath = ""
+	}
+
+	return &DepInfo{
+		Scheme: "file",
+		Repo:   root,
+		Path:   subPath,
+		Block:  block,
+		Tag:    tag,
 	}, nil
 }
 
@@ -116,15 +235,204 @@ func extractBlockAndTag(fragment string) (string, string) {
 }
 
 // GitClient is an interface that abstracts Git operations.
+// Clone checks out ref, or the default branch when ref is empty, into dest.
 type GitClient interface {
-	Clone(host, owner, repo, dest string) error
+	Clone(host, owner, repo, ref, dest string) error
 	Checkout(path, branch string) error
 	DefaultBranch() string
 }
 
+// checkout is a repository checked out at a ref.
+type checkout struct {
+	fs.FS
+	// name prefixes the names of templates parsed from the checkout.
+	name string
+	// commit is the commit the checkout resolved to, if known without inspecting the worktree.
+	commit string
+	// dir is the directory of the checkout on disk, if any.
+	dir string
+	// clone reports whether dir is a git clone of its own, which can be checked out at other refs.
+	clone   bool
+	cleanup func()
+}
+
+// path returns the path of the dependency path p within the checkout.
+func (c *checkout) path(p string) string {
+	if p = strings.Trim(path.Clean("/"+p), "/"); p == "" {
+		return "."
+	}
+	return p
+}
+
+// headCommit returns the commit of the checkout, or an empty string if it is not a git checkout.
+func (c *checkout) headCommit() string {
+	if c.commit != "" || c.dir == "" {
+		return c.commit
+	}
+
+	r, err := git.PlainOpen(c.dir)
+	if err != nil {
+		return ""
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return ""
+	}
+
+	return head.Hash().String()
+}
+
+// checkoutName returns the name of the checkout of dep at ref, which prefixes the names of its templates.
+func checkoutName(dep *DepInfo, ref string) string {
+	name := path.Join(dep.Host, dep.Owner, dep.Repo)
+	if dep.IsLocal() {
+		name = dep.Repo
+	}
+	if ref != "" {
+		name += "@" + ref
+	}
+	return name
+}
+
+// checkoutDep makes the repository of dep available at the requested ref.
+// The caller must call the cleanup function of the checkout once it is no longer needed.
+// When a lockfile is configured the ref is resolved from it and the resolved dependency is recorded.
+func (e *Executor) checkoutDep(dep *DepInfo) (*checkout, error) {
+	return e.checkoutDepFrom(nil, dep)
+}
+
+// checkoutDepFrom is checkoutDep reusing prev, a checkout of another ref of the same repository, if it is not nil.
+// A clone is checked out at the ref of dep through GitClient.Checkout instead of cloning the repository again.
+// prev must not be used afterwards, it is returned or cleaned up.
+func (e *Executor) checkoutDepFrom(prev *checkout, dep *DepInfo) (*checkout, error) {
+	ref := dep.Tag
+	if ref == "" && !dep.IsLocal() {
+		ref = e.git.DefaultBranch()
+	}
+
+	var locked LockEntry
+	var isLocked bool
+	checkoutRef := ref
+	// local dependencies are machine specific and are never locked
+	useLock := e.lock != nil && !dep.IsLocal()
+
+	if useLock && !e.lockUpdate {
+		if locked, isLocked = e.lock.Lookup(*dep, ref); isLocked {
+			checkoutRef = locked.Commit
+		}
+	}
+
+	co, err := e.switchRepo(prev, dep, checkoutRef)
+	if err != nil {
+		return nil, err
+	}
+
+	// the manifest records the commit of the template the project is generated from
+	if e.manifest != nil && e.manifest.Source == dep.String() {
+		e.manifest.Commit = co.headCommit()
+	}
+
+	if !useLock {
+		return co, nil
+	}
+
+	commit := co.commit
+	if commit == "" {
+		if commit, err = resolveCommit(co.dir); err != nil {
+			co.cleanup()
+			return nil, fmt.Errorf("failed to resolve commit: %w", err)
+		}
+	}
+
+	hash, err := hashFS(co, co.path(dep.Path))
+	if err != nil {
+		co.cleanup()
+		return nil, fmt.Errorf("failed to hash %s: %w", dep, err)
+	}
+
+	if isLocked && hash != locked.Hash {
+		co.cleanup()
+		return nil, fmt.Errorf("content of %s does not match lockfile: expected hash %s, got %s", dep, locked.Hash, hash)
+	}
+
+	e.lock.Record(LockEntry{
+		Host:   dep.Host,
+		Owner:  dep.Owner,
+		Repo:   dep.Repo,
+		Path:   dep.Path,
+		Ref:    ref,
+		Commit: commit,
+		Hash:   hash,
+	})
+
+	return co, nil
+}
+
+// switchRepo checks out the clone prev at ref, or the repository of dep if prev is nil or not a clone.
+func (e *Executor) switchRepo(prev *checkout, dep *DepInfo, ref string) (*checkout, error) {
+	if prev == nil {
+		return e.fetchRepo(dep, ref)
+	}
+
+	if !prev.clone {
+		prev.cleanup()
+		return e.fetchRepo(dep, ref)
+	}
+
+	if err := e.git.Checkout(prev.dir, ref); err != nil {
+		prev.cleanup()
+		return nil, fmt.Errorf("failed to checkout ref %s: %w", ref, err)
+	}
+
+	prev.name, prev.commit = checkoutName(dep, ref), ""
+	return prev, nil
+}
+
+// fetchRepo checks out the repository of dep at ref from the cache, into memory when the
+// git client supports it, or into a temporary directory.
+func (e *Executor) fetchRepo(dep *DepInfo, ref string) (*checkout, error) {
+	const tempDirPrefix = "templit_clone_"
+
+	if e.cache != nil && !dep.IsLocal() {
+		dir, err := e.cache.Fetch(e.git, dep.Remote(), dep.Owner, dep.Repo, ref)
+		if err != nil {
+			return nil, err
+		}
+		return &checkout{FS: os.DirFS(dir), name: checkoutName(dep, ref), dir: dir, cleanup: func() {}}, nil
+	}
+
+	if client, ok := e.git.(FSGitClient); ok {
+		fsys, commit, err := client.CloneFS(dep.Remote(), dep.Owner, dep.Repo, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone repo: %w", err)
+		}
+
+		return &checkout{FS: fsys, name: checkoutName(dep, ref), commit: commit, cleanup: func() {}}, nil
+	}
+
+	tempDir, err := os.MkdirTemp("", tempDirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	if err := e.git.Clone(dep.Remote(), dep.Owner, dep.Repo, ref, tempDir); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to clone repo: %w", err)
+	}
+
+	return &checkout{FS: os.DirFS(tempDir), name: checkoutName(dep, ref), dir: tempDir, clone: !dep.IsLocal(), cleanup: cleanup}, nil
+}
+
 // DefaultGitClient provides a default implementation for the GitClient interface.
 type DefaultGitClient struct {
-	Token         string
+	// Token is used as HTTP basic auth password for hosts without an entry in Credentials.
+	Token string
+	// Credentials configures the authentication per host or host and owner prefix.
+	Credentials map[string]Credential
+	// Rewrites are applied to repository URLs before they are cloned.
+	Rewrites      []Rewrite
 	defaultBranch string
 }
 
@@ -145,40 +453,161 @@ func (d *DefaultGitClient) DefaultBranch() string {
 	return d.defaultBranch
 }
 
-// Clone clones a Git repository to the given destination.
-func (d *DefaultGitClient) Clone(host, owner, repo, dest string) error {
-	repoURL := fmt.Sprintf("%s/%s/%s.git", host, owner, repo)
-	if !strings.HasPrefix(repoURL, "https://") {
-		repoURL = fmt.Sprintf("https://%s", repoURL)
-	}
-
-	var auth *http.BasicAuth
-	if d.Token != "" {
-		auth = &http.BasicAuth{
-			Username: "username", // this can be anything except an empty string
-			Password: d.Token,
+// Clone clones a Git repository at the given ref to the given destination.
+// Local dependencies, which have the host file://, are copied from the directory repo instead.
+// Branches and tags are cloned with a depth of 1, only refs that cannot be found
+// that way, such as commit hashes, fall back to a full clone followed by a checkout.
+func (d *DefaultGitClient) Clone(host, owner, repo, ref, dest string) error {
+	if host == "file://" {
+		if ref == "" {
+			if err := copyTree(repo, dest); err != nil {
+				return fmt.Errorf("failed to copy %s: %w", repo, err)
+			}
+			return nil
 		}
-	}
 
-	_, err := git.PlainClone(dest, false, &git.CloneOptions{
-		URL:  repoURL,
-		Auth: auth,
-	})
+		_, err := copyLocalRef(repo, ref, osfs.New(dest))
+		return err
+	}
 
+	repoURL, auth, err := d.Endpoint(host, owner, repo)
 	if err != nil {
-		return fmt.Errorf("failed to clone repo %s: %w", repoURL, err)
+		return err
 	}
 
-	return nil
+	_, err = cloneRef(func(opts *git.CloneOptions) (*git.Repository, error) {
+		// remove the partial clone of a previous attempt
+		if err := os.RemoveAll(filepath.Join(dest, ".git")); err != nil {
+			return nil, fmt.Errorf("failed to clean up clone: %w", err)
+		}
+		return git.PlainClone(dest, false, opts)
+	}, repoURL, auth, ref)
+
+	return err
 }
 
 // Checkout checks out a branch, tag or commit hash in a Git repository.
+// Refs missing from the repository, like those of a shallow clone of another ref, are fetched from its origin first.
 func (d *DefaultGitClient) Checkout(path, ref string) error {
 	r, err := git.PlainOpen(path)
 	if err != nil {
 		return err
 	}
 
+	if err := checkoutRef(r, ref); err == nil {
+		return nil
+	}
+
+	if err := d.fetchRef(r, ref); err != nil {
+		return err
+	}
+
+	return checkoutRef(r, ref)
+}
+
+// fetchRef fetches ref from the origin of r. Branches and tags are fetched with a depth of 1,
+// other refs, such as commit hashes, fall back to fetching the full history of all branches and tags.
+func (d *DefaultGitClient) fetchRef(r *git.Repository, ref string) error {
+	remote, err := r.Remote("origin")
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+
+	var auth transport.AuthMethod
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		if u, err := url.Parse(urls[0]); err == nil && u.Scheme != "" {
+			if auth, err = d.auth(u); err != nil {
+				return err
+			}
+		}
+	}
+
+	fetch := func(depth int, specs ...config.RefSpec) error {
+		err := r.Fetch(&git.FetchOptions{RemoteName: "origin", RefSpecs: specs, Depth: depth, Auth: auth, Tags: git.NoTags})
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil
+		}
+		return err
+	}
+
+	if !isFullHash(ref) {
+		for _, spec := range []config.RefSpec{
+			config.RefSpec("+refs/heads/" + ref + ":refs/remotes/origin/" + ref),
+			config.RefSpec("+refs/tags/" + ref + ":refs/tags/" + ref),
+		} {
+			err := fetch(1, spec)
+			if err == nil {
+				return nil
+			}
+
+			if !isRefNotFound(err) {
+				return fmt.Errorf("failed to fetch %s: %w", ref, err)
+			}
+		}
+	}
+
+	if err := fetch(0, "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+// cloneRef clones ref from repoURL by calling clone, which may be called multiple times.
+// Branches and tags are cloned with a depth of 1, other refs fall back to a full clone followed by a checkout.
+func cloneRef(clone func(*git.CloneOptions) (*git.Repository, error), repoURL string, auth transport.AuthMethod, ref string) (*git.Repository, error) {
+	opts := &git.CloneOptions{
+		URL:          repoURL,
+		Auth:         auth,
+		Depth:        1,
+		SingleBranch: true,
+		Tags:         git.NoTags,
+	}
+
+	if ref == "" {
+		r, err := clone(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone repo %s: %w", repoURL, err)
+		}
+		return r, nil
+	}
+
+	if !isFullHash(ref) {
+		for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+			opts.ReferenceName = name
+			opts.Tags = git.NoTags
+			if name.IsTag() {
+				opts.Tags = git.TagFollowing
+			}
+
+			r, err := clone(opts)
+			if err == nil {
+				return r, nil
+			}
+
+			if !isRefNotFound(err) {
+				return nil, fmt.Errorf("failed to clone repo %s: %w", repoURL, err)
+			}
+		}
+	}
+
+	r, err := clone(&git.CloneOptions{
+		URL:  repoURL,
+		Auth: auth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repo %s: %w", repoURL, err)
+	}
+
+	if err := checkoutRef(r, ref); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// checkoutRef checks out a branch, tag or commit hash in the worktree of r.
+func checkoutRef(r *git.Repository, ref string) error {
 	w, err := r.Worktree()
 	if err != nil {
 		return err
@@ -200,10 +629,14 @@ func (d *DefaultGitClient) Checkout(path, ref string) error {
 		return nil // Tag checked out successfully
 	}
 
-	// If tag checkout also fails, try using it as a commit hash
-	commitHash := plumbing.NewHash(ref)
+	// If tag checkout also fails, try using it as a full or abbreviated commit hash
+	commitHash, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return fmt.Errorf("failed to checkout reference %s: %w", ref, err)
+	}
+
 	err = w.Checkout(&git.CheckoutOptions{
-		Hash: commitHash,
+		Hash: *commitHash,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to checkout reference %s: %w", ref, err)
@@ -211,3 +644,23 @@ func (d *DefaultGitClient) Checkout(path, ref string) error {
 
 	return nil
 }
+
+// isFullHash reports whether ref is a full hexadecimal commit hash.
+func isFullHash(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+
+	for _, r := range ref {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isRefNotFound reports whether err was caused by a ref missing on the remote.
+func isRefNotFound(err error) bool {
+	return errors.Is(err, plumbing.ErrReferenceNotFound) || errors.Is(err, git.NoMatchingRefSpecError{})
+}
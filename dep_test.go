@@ -1,9 +1,12 @@
 package templit_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/euforic/templit"
+	git "github.com/go-git/go-git/v5"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -80,6 +83,64 @@ func TestParseDepURL(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:   "SSH URL",
+			rawURL: "ssh://git@github.com/owner/repo/some/path#test_block@v1.2.3",
+			expected: &templit.DepInfo{
+				Scheme: "ssh",
+				User:   "git",
+				Host:   "github.com",
+				Owner:  "owner",
+				Repo:   "repo",
+				Path:   "some/path",
+				Block:  "test_block",
+				Tag:    "v1.2.3",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "SCP-like SSH URL",
+			rawURL: "git@github.com:owner/repo/some/path@v1.2.3",
+			expected: &templit.DepInfo{
+				Scheme: "ssh",
+				User:   "git",
+				Host:   "github.com",
+				Owner:  "owner",
+				Repo:   "repo",
+				Path:   "some/path",
+				Tag:    "v1.2.3",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Relative local path",
+			rawURL: "./templates/basic/greeting.txt#block@v1.2.3",
+			expected: &templit.DepInfo{
+				Scheme: "file",
+				Repo:   "templates/basic",
+				Path:   "greeting.txt",
+				Block:  "block",
+				Tag:    "v1.2.3",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "Local file URL with root separator",
+			rawURL: "file:///src/templates//basic/greeting.txt@main",
+			expected: &templit.DepInfo{
+				Scheme: "file",
+				Repo:   "/src/templates",
+				Path:   "basic/greeting.txt",
+				Tag:    "main",
+			},
+			wantErr: false,
+		},
+		{
+			name:     "Unsupported scheme",
+			rawURL:   "ftp://github.com/owner/repo",
+			expected: nil,
+			wantErr:  true,
+		},
 		{
 			name:     "Invalid URL missing repo name",
 			rawURL:   "https://github.com/owner",
@@ -102,3 +163,88 @@ func TestParseDepURL(t *testing.T) {
 		})
 	}
 }
+
+// TestDepInfo_String tests that the String function returns a URL that parses to the same DepInfo.
+func TestDepInfo_String(t *testing.T) {
+	tests := []string{
+		"github.com/owner/repo/path/to/file#block@v1.2.3",
+		"ssh://git@github.com/owner/repo/path@main",
+		"file://../templates//basic/greeting.txt#block@v1",
+	}
+
+	for _, rawURL := range tests {
+		t.Run(rawURL, func(t *testing.T) {
+			dep, err := templit.ParseDepURL(rawURL)
+			if err != nil {
+				t.Fatalf("failed to parse %s: %v", rawURL, err)
+			}
+
+			if diff := cmp.Diff(rawURL, dep.String()); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestDefaultGitClient_Clone tests cloning branches, tags and commit hashes from a repository served over the file transport.
+func TestDefaultGitClient_Clone(t *testing.T) {
+	base := t.TempDir()
+	repoDir := filepath.Join(base, "owner", "repo.git")
+
+	r, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "greeting.txt", "v1")
+
+	first, err := r.Head()
+	if err != nil {
+		t.Fatalf("failed to read head: %v", err)
+	}
+
+	if _, err := r.CreateTag("v1", first.Hash(), nil); err != nil {
+		t.Fatalf("failed to tag: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "greeting.txt", "v2")
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected string
+		wantErr  bool
+	}{
+		{name: "default branch", ref: "", expected: "v2"},
+		{name: "branch", ref: "master", expected: "v2"},
+		{name: "tag", ref: "v1", expected: "v1"},
+		{name: "commit hash", ref: first.Hash().String(), expected: "v1"},
+		{name: "abbreviated commit hash", ref: first.Hash().String()[:8], expected: "v1"},
+		{name: "unknown ref", ref: "unknown", wantErr: true},
+	}
+
+	client := templit.NewDefaultGitClient("master", "")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := t.TempDir()
+
+			err := client.Clone("file://"+base, "owner", "repo", tt.ref, dest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			content, err := os.ReadFile(filepath.Join(dest, "greeting.txt"))
+			if err != nil {
+				t.Fatalf("failed to read cloned file: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, string(content)); diff != "" {
+				t.Errorf("content mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
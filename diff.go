@@ -0,0 +1,302 @@
+package templit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes in a unified diff.
+const diffContext = 3
+
+// diffOp is a line of a diff. Kind is ' ' for unchanged, '-' for removed and '+' for added lines.
+type diffOp struct {
+	Kind byte
+	Line string
+}
+
+// UnifiedDiff returns the unified diff between oldText and newText, or an empty string if they are equal.
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	// oldLines and newLines count the lines of each side before each op
+	oldLines := make([]int, len(ops)+1)
+	newLines := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldLines[i+1], newLines[i+1] = oldLines[i], newLines[i]
+		if op.Kind != '+' {
+			oldLines[i+1]++
+		}
+		if op.Kind != '-' {
+			newLines[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			i++
+			continue
+		}
+
+		// changes separated by at most 2*diffContext unchanged lines share a hunk
+		start, end := max(i-diffContext, 0), i
+		for j := i; j < len(ops); j++ {
+			if ops[j].Kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end = min(end+diffContext, len(ops))
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldLines[start], oldLines[end]-oldLines[start]),
+			hunkRange(newLines[start], newLines[end]-newLines[start]))
+
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.Kind)
+			b.WriteString(strings.TrimSuffix(op.Line, "\n"))
+			b.WriteByte('\n')
+			if !strings.HasSuffix(op.Line, "\n") {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+
+		i = end
+	}
+
+	return b.String()
+}
+
+// hunkRange formats the start and length of a hunk. start is the number of lines before the hunk.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// splitLines splits text into lines that keep their trailing newline.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffLines returns the shortest edit script turning a into b using the linear space variant of the Myers
+// algorithm. Removed lines come before added lines in every changed block.
+func diffLines(a, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	ops = diffRange(ops, a, b)
+
+	// order each block of changes as removals followed by additions
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(ops) && ops[j].Kind != ' ' {
+			j++
+		}
+		sort.SliceStable(ops[i:j], func(x, y int) bool {
+			return ops[i+x].Kind == '-' && ops[i+y].Kind == '+'
+		})
+		i = j
+	}
+
+	return ops
+}
+
+// diffRange appends the edit script turning a into b to ops. Common leading and trailing lines are kept, the rest is
+// split at the middle of a shortest edit script and both halves are diffed recursively.
+func diffRange(ops []diffOp, a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, diffOp{Kind: ' ', Line: a[prefix]})
+		prefix++
+	}
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, diffOp{Kind: '+', Line: line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, diffOp{Kind: '-', Line: line})
+		}
+	default:
+		x, y := middleSnake(a, b)
+		ops = diffRange(ops, a[:x], b[:y])
+		ops = diffRange(ops, a[x:], b[y:])
+	}
+
+	for _, line := range common {
+		ops = append(ops, diffOp{Kind: ' ', Line: line})
+	}
+
+	return ops
+}
+
+// middleSnake returns the point where the forward and backward searches for a shortest edit script turning a into b
+// meet. a and b must not be empty and must differ in their first and last lines, so the point splits them into
+// two smaller problems. Only two vectors of len(a)+len(b) entries are kept.
+func middleSnake(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	size := 2*maxD + 3
+
+	// forward holds the furthest x reached on every diagonal from the start, backward the furthest x from the end
+	forward := make([]int, size)
+	backward := make([]int, size)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// the paths can only meet while searching forward if delta is odd
+	odd := delta%2 != 0
+
+	var fStart, fEnd, bStart, bEnd int
+	for d := 0; d <= maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[i] = x
+
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				if j := offset + delta - k; j >= 0 && j < size && backward[j] != -1 && x >= n-backward[j] {
+					return x, y
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			i := offset + k
+			var x int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				if j := offset + delta - k; j >= 0 && j < size && forward[j] != -1 {
+					fx := forward[j]
+					if fx >= n-x {
+						return fx, offset + fx - j
+					}
+				}
+			}
+		}
+	}
+
+	// not reached for inputs with a difference, split into a removal and an addition
+	return n, 0
+}
+
+// Drift reports whether applying the plan would change the output directory.
+func (p *Plan) Drift() bool {
+	for _, entry := range p.Sorted() {
+		if entry.Status != PlanUnchanged {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteDiff writes unified diffs between the files in the output directory and the files of the plan to w.
+func (p *Plan) WriteDiff(w io.Writer) error {
+	for _, entry := range p.Sorted() {
+		if entry.Dir || entry.Status == PlanUnchanged {
+			continue
+		}
+
+		oldName, newName := "a/"+filepath.ToSlash(entry.Path), "b/"+filepath.ToSlash(entry.Path)
+		var oldText, newText string
+
+		if entry.Status != PlanNew {
+			content, err := os.ReadFile(filepath.Join(p.Root, entry.Path))
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", entry.Path, err)
+			}
+			oldText = string(content)
+		} else {
+			oldName = "/dev/null"
+		}
+
+		if entry.Status == PlanRemoved {
+			newName = "/dev/null"
+		} else {
+			newText = string(entry.Data)
+		}
+
+		if _, err := io.WriteString(w, UnifiedDiff(oldName, newName, oldText, newText)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
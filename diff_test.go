@@ -0,0 +1,138 @@
+package templit_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestUnifiedDiff tests the UnifiedDiff function.
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected string
+	}{
+		{
+			name:    "equal",
+			oldText: "a\nb\n",
+			newText: "a\nb\n",
+		},
+		{
+			name:     "changed line",
+			oldText:  "a\nb\nc\n",
+			newText:  "a\nB\nc\n",
+			expected: "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "new file",
+			oldText:  "",
+			newText:  "a\n",
+			expected: "--- a/f\n+++ b/f\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:     "missing newline",
+			oldText:  "a\n",
+			newText:  "a",
+			expected: "--- a/f\n+++ b/f\n@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+		{
+			name:     "separate hunks",
+			oldText:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			newText:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: "--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := templit.UnifiedDiff("a/f", "b/f", tt.oldText, tt.newText)
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Errorf("diff mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestPlan_WriteDiff tests that a plan reports drift against the output directory.
+func TestPlan_WriteDiff(t *testing.T) {
+	data := map[string]interface{}{
+		"Name":        "John",
+		"Title":       "Project",
+		"Description": "This is a test project.",
+		"Detail":      "more info here.",
+	}
+
+	outputDir := t.TempDir()
+	if err := templit.NewExecutor(nil).WalkAndProcessDir("test_data/templates/basic_test", outputDir, data); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	plan := templit.NewPlan(outputDir)
+	if err := templit.NewExecutor(nil, templit.WithOutput(plan)).WalkAndProcessDir("test_data/templates/basic_test", outputDir, data); err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+
+	if plan.Drift() {
+		t.Errorf("expected no drift after rendering")
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, "greeting.txt"), []byte("Hello, Jane!\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	plan = templit.NewPlan(outputDir)
+	if err := templit.NewExecutor(nil, templit.WithOutput(plan)).WalkAndProcessDir("test_data/templates/basic_test", outputDir, data); err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+
+	if !plan.Drift() {
+		t.Errorf("expected drift after editing the output")
+	}
+
+	var b strings.Builder
+	if err := plan.WriteDiff(&b); err != nil {
+		t.Fatalf("failed to write diff: %v", err)
+	}
+
+	expected := "--- a/greeting.txt\n+++ b/greeting.txt\n@@ -1 +1 @@\n-Hello, Jane!\n+Hello, John!\n"
+	if diff := cmp.Diff(expected, b.String()); diff != "" {
+		t.Errorf("diff mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestUnifiedDiff_LargeRewrite tests that diffing a completely rewritten file uses memory linear in its size.
+func TestUnifiedDiff_LargeRewrite(t *testing.T) {
+	const lines = 5000
+
+	var oldText, newText strings.Builder
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&oldText, "old line %d\n", i)
+		fmt.Fprintf(&newText, "new line %d\n", i)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	result := templit.UnifiedDiff("a/f", "b/f", oldText.String(), newText.String())
+	runtime.ReadMemStats(&after)
+
+	header := fmt.Sprintf("--- a/f\n+++ b/f\n@@ -1,%d +1,%d @@\n-old line 0\n", lines, lines)
+	if !strings.HasPrefix(result, header) {
+		t.Fatalf("expected the diff to start with %q, got %q", header, result[:len(header)])
+	}
+
+	if got := strings.Count(result, "\n-old line"); got != lines {
+		t.Errorf("expected %d removed lines, got %d", lines, got)
+	}
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 64<<20 {
+		t.Errorf("expected less than 64 MB to be allocated, got %d MB", allocated>>20)
+	}
+}
@@ -112,11 +112,10 @@ func (app *App) renderTemplate(this js.Value, args []js.Value) interface{} {
 	tmplStr := args[0].String()
 	jsonStr := args[1].String()
 
-	executor := templit.NewExecutor(templit.NewDefaultGitClient("main", ""))
+	// the playground has no filesystem, so dependencies are cloned into memory
+	executor := templit.NewExecutor(templit.NewMemGitClient("main", ""))
 	funcs := templit.DefaultFuncMap
-	funcs["embed"] = func(name string) string {
-		return fmt.Sprintf("Embed (not enabled): %s", name)
-	}
+	funcs["embed"] = executor.EmbedFunc
 	funcs["import"] = func(name string) string {
 		return fmt.Sprintf("Import (not enabled): %s", name)
 	}
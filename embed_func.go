@@ -2,9 +2,8 @@ package templit
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 	"path"
-	"path/filepath"
 )
 
 // EmbedFunc returns a template function that can be used to process and embed a template from a remote git repository.
@@ -16,6 +15,7 @@ import (
 //     ```
 //     {{ embed "<host>/<owner>/<repo>/<path>@<tag_or_hash_or_branch>" . }}
 //     {{ embed "<host>/<owner>/<repo>#<block>@<tag_or_hash_or_branch>" . }}
+//     {{ embed "file://<dir>//<path>@<tag_or_hash_or_branch>" . }}
 //     ```
 //
 // Placeholders:
@@ -24,41 +24,28 @@ import (
 //   - `<repo>`: Repository name.
 //   - `<path>`: Path to the desired file or directory within the repository.
 //   - `<block>`: Specific template block name.
+//   - `<dir>`: Local directory, optionally inside a git repository. Relative paths are resolved against the working directory.
 //   - `<tag_or_hash_or_branch>`: Specific Git reference (tag, commit hash, or branch name).
 func (e *Executor) EmbedFunc(remotePath string, data interface{}) (string, error) {
-	const tempDirPrefix = "templit_clone_"
-
 	depInfo, err := ParseDepURL(remotePath)
 	if err != nil {
 		return "", err
 	}
 
-	if depInfo.Tag == "" {
+	if depInfo.Tag == "" && !depInfo.IsLocal() {
 		depInfo.Tag = e.git.DefaultBranch()
 	}
 
-	tempDir, err := os.MkdirTemp("", tempDirPrefix)
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp dir: %w", err)
-	}
-	defer os.RemoveAll(tempDir) // Cleanup
-
-	err = e.git.Clone(depInfo.Host, depInfo.Owner, depInfo.Repo, tempDir)
+	co, err := e.checkoutDep(depInfo)
 	if err != nil {
-		return "", fmt.Errorf("failed to clone repo: %w", err)
-	}
-
-	if depInfo.Tag != "" && depInfo.Tag != e.git.DefaultBranch() {
-		err = e.git.Checkout(tempDir, depInfo.Tag)
-		if err != nil {
-			return "", fmt.Errorf("failed to checkout branch: %w", err)
-		}
+		return "", err
 	}
+	defer co.cleanup()
 
 	// templatePath is the path to the template file or directory
-	templatePath := path.Join(tempDir, depInfo.Path)
+	templatePath := co.path(depInfo.Path)
 
-	if err := e.ParsePath(filepath.Dir(templatePath)); err != nil {
+	if err := e.parseFS(co, path.Dir(templatePath), co.name); err != nil {
 		return "", fmt.Errorf("failed to create executor: %w", err)
 	}
 
@@ -66,5 +53,20 @@ func (e *Executor) EmbedFunc(remotePath string, data interface{}) (string, error
 		return e.Render(depInfo.Block, data)
 	}
 
-	return e.Render(path.Join(tempDir, depInfo.Path), data)
+	content, err := fs.ReadFile(co, templatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+
+	if e.isCopyOnly(templatePath, content) {
+		return string(content), nil
+	}
+
+	// the front matter of an embedded file may skip it or mark it as raw, its output settings do not apply
+	_, rendered, _, err := e.renderFile(templateName(co.name, templatePath), content, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", templatePath, err)
+	}
+
+	return string(rendered), nil
 }
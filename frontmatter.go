@@ -0,0 +1,246 @@
+package templit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterFence opens and closes the front matter block at the start of a template file.
+const frontMatterFence = "---"
+
+// FrontMatter controls how a template file is generated. It is read from a YAML block, which may also be
+// written as JSON, between two "---" lines at the start of the file and is stripped from the output:
+//
+//	---
+//	path: "cmd/{{.Name}}/main.go"
+//	mode: "0755"
+//	skip: "{{not .WithCLI}}"
+//	---
+//
+// Path and Skip are rendered as templates with the data of the file. A block without any front matter field,
+// like a YAML document between separators, is kept as part of the template. Blocks with a front matter field
+// must not have unknown keys, so typos in field names are reported instead of being generated.
+type FrontMatter struct {
+	// Path replaces the output path of the file. It is relative to the directory the file is generated in,
+	// or to the output directory if it starts with "/".
+	Path string `yaml:"path"`
+	// Mode is the octal file mode of the generated file, like "0755".
+	Mode string `yaml:"mode"`
+	// Skip skips the file if it renders to "true".
+	Skip string `yaml:"skip"`
+	// Raw copies the file without rendering it as a template.
+	Raw bool `yaml:"raw"`
+	// Delims are the left and right action delimiters of the file.
+	Delims []string `yaml:"delims"`
+	// Conflict is the conflict policy of the file. Conflict rules of the executor take precedence over it.
+	Conflict ConflictPolicy `yaml:"conflict"`
+}
+
+// parseFrontMatter splits the content of a template file into its front matter and its body.
+// The front matter is nil if the file does not start with a front matter block.
+func parseFrontMatter(content []byte) (*FrontMatter, []byte, error) {
+	text := string(content)
+	if !strings.HasPrefix(text, frontMatterFence+"\n") && !strings.HasPrefix(text, frontMatterFence+"\r\n") {
+		return nil, content, nil
+	}
+
+	// the block ends at the next line consisting of the fence
+	blockStart := strings.Index(text, "\n") + 1
+	var block string
+	bodyStart := -1
+	for offset := blockStart; offset < len(text); {
+		line, _, found := strings.Cut(text[offset:], "\n")
+		if strings.TrimRight(line, "\r") == frontMatterFence {
+			block = text[blockStart:offset]
+			bodyStart = offset + len(line)
+			if found {
+				bodyStart++
+			}
+			break
+		}
+		offset += len(line) + 1
+	}
+	if bodyStart < 0 {
+		return nil, content, nil
+	}
+
+	fm := &FrontMatter{}
+	decoder := yaml.NewDecoder(strings.NewReader(block))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(fm); err != nil && !errors.Is(err, io.EOF) {
+		// blocks that are not front matter, like a YAML document between separators, belong to the template
+		if !hasFrontMatterField(block) {
+			return nil, content, nil
+		}
+		return nil, nil, fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	if fm.Delims != nil && len(fm.Delims) != 2 {
+		return nil, nil, fmt.Errorf("front matter delims must be a left and a right delimiter, got %q", fm.Delims)
+	}
+
+	if fm.Conflict != "" {
+		if _, err := ParseConflictPolicy(string(fm.Conflict)); err != nil {
+			return nil, nil, fmt.Errorf("invalid front matter conflict: %w", err)
+		}
+	}
+
+	return fm, content[bodyStart:], nil
+}
+
+// hasFrontMatterField reports whether block is a YAML mapping with at least one top-level FrontMatter field.
+func hasFrontMatterField(block string) bool {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(block), &doc); err != nil || len(doc.Content) == 0 {
+		return false
+	}
+
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return false
+	}
+
+	fields := reflect.TypeOf(FrontMatter{})
+	for i := 0; i < len(mapping.Content); i += 2 {
+		for j := 0; j < fields.NumField(); j++ {
+			if fields.Field(j).Tag.Get("yaml") == mapping.Content[i].Value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// delims returns the action delimiters of a file with the front matter fm, which may be nil.
+// Files without front matter delimiters use the delimiters of the executor.
+func (e *Executor) delims(fm *FrontMatter) (string, string) {
+	if fm == nil || fm.Delims == nil {
+		return e.leftDelim, e.rightDelim
+	}
+	return fm.Delims[0], fm.Delims[1]
+}
+
+// parseTemplate parses the template file content as the template name. It returns the front matter of the file,
+// the parsed template and the body without the front matter. Raw files are not parsed and have no template.
+func (e *Executor) parseTemplate(name string, content []byte) (*FrontMatter, *template.Template, []byte, error) {
+	fm, body, err := parseFrontMatter(content)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if fm != nil && fm.Raw {
+		return fm, nil, body, nil
+	}
+
+	left, right := e.delims(fm)
+	tmpl, err := e.New(name).Delims(left, right).Parse(string(body))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	e.recordUsage(name, string(body), left, right)
+
+	return fm, tmpl, body, nil
+}
+
+// renderFile renders the template file content named name with data and returns its front matter, which is nil
+// if the file has none, the generated content and false if the front matter skips the file.
+func (e *Executor) renderFile(name string, content []byte, data interface{}) (*FrontMatter, []byte, bool, error) {
+	fm, tmpl, body, err := e.parseTemplate(name, content)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("error parsing template: %w", err)
+	}
+
+	if fm != nil && fm.Skip != "" {
+		skip, err := e.renderFrontMatter(fm, "skip", fm.Skip, data)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		skipped, err := strconv.ParseBool(skip)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("front matter skip must render to a boolean, got %q", skip)
+		}
+		if skipped {
+			return fm, nil, false, nil
+		}
+	}
+
+	if tmpl == nil {
+		return fm, body, true, nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, nil, false, fmt.Errorf("error executing template: %w", err)
+	}
+
+	return fm, buf.Bytes(), true, nil
+}
+
+// applyFrontMatter returns the output path, the file mode and the conflict policy of a file generated at outPath
+// with mode below the output directory root, as changed by its front matter fm.
+func (e *Executor) applyFrontMatter(fm *FrontMatter, root, outPath string, mode fs.FileMode, data interface{}) (string, fs.FileMode, ConflictPolicy, error) {
+	if fm.Path != "" {
+		p, err := e.renderFrontMatter(fm, "path", fm.Path, data)
+		if err != nil {
+			return "", 0, "", err
+		}
+
+		if outPath, err = frontMatterPath(root, outPath, p); err != nil {
+			return "", 0, "", err
+		}
+	}
+
+	if fm.Mode != "" {
+		perm, err := strconv.ParseUint(fm.Mode, 8, 32)
+		if err != nil {
+			return "", 0, "", fmt.Errorf("front matter mode must be an octal file mode, got %q", fm.Mode)
+		}
+		mode = fs.FileMode(perm).Perm()
+	}
+
+	return outPath, mode, fm.Conflict, nil
+}
+
+// renderFrontMatter renders the value of the front matter field with data, using the delimiters of the file.
+func (e *Executor) renderFrontMatter(fm *FrontMatter, field, value string, data interface{}) (string, error) {
+	left, right := e.delims(fm)
+	tmpl, err := e.New("temp").Delims(left, right).Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("error parsing front matter %s: %w", field, err)
+	}
+	e.recordUsage(field, value, left, right)
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error executing front matter %s: %w", field, err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// frontMatterPath resolves the front matter path p of the file generated at outPath below the output directory root.
+func frontMatterPath(root, outPath, p string) (string, error) {
+	resolved := filepath.Join(filepath.Dir(outPath), filepath.FromSlash(p))
+	if strings.HasPrefix(p, "/") {
+		resolved = filepath.Join(root, filepath.FromSlash(p))
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("front matter path %q is outside of the output directory", p)
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,149 @@
+package templit_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestFrontMatter tests that the front matter of template files controls how they are generated.
+func TestFrontMatter(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		existing      map[string]string
+		expectedFiles map[string]templit.MemFile
+		expectedError string
+	}{
+		{
+			name: "path",
+			files: map[string]string{
+				"app/docs/readme.tmpl": "---\npath: \"{{.Name}}.md\"\n---\n# {{.Name}}",
+				"app/docs/root.tmpl":   "---\npath: /ROOT.md\n---\nroot",
+			},
+			expectedFiles: map[string]templit.MemFile{
+				"docs/demo.md": {Data: []byte("# demo"), Mode: 0644},
+				"ROOT.md":      {Data: []byte("root"), Mode: 0644},
+			},
+		},
+		{
+			name: "json",
+			files: map[string]string{
+				"app/run.sh": "---\n{\"mode\": \"0755\"}\n---\necho {{.Name}}",
+			},
+			expectedFiles: map[string]templit.MemFile{
+				"run.sh": {Data: []byte("echo demo"), Mode: 0755},
+			},
+		},
+		{
+			name: "read-only mode",
+			files: map[string]string{
+				"app/secret.txt": "---\nmode: \"0444\"\n---\nsecret",
+			},
+			expectedFiles: map[string]templit.MemFile{
+				"secret.txt": {Data: []byte("secret"), Mode: 0444},
+			},
+		},
+		{
+			name: "skip",
+			files: map[string]string{
+				"app/skipped.txt": "---\nskip: \"{{eq .Name \\\"demo\\\"}}\"\n---\nskipped",
+				"app/kept.txt":    "---\nskip: false\n---\nkept",
+			},
+			expectedFiles: map[string]templit.MemFile{
+				"kept.txt": {Data: []byte("kept"), Mode: 0644},
+			},
+		},
+		{
+			name: "raw and delims",
+			files: map[string]string{
+				"app/raw.txt":    "---\nraw: true\n---\n{{.Name}}",
+				"app/delims.txt": "---\ndelims: [\"[[\", \"]]\"]\n---\n{{.Name}} [[.Name]]",
+			},
+			expectedFiles: map[string]templit.MemFile{
+				"raw.txt":    {Data: []byte("{{.Name}}"), Mode: 0644},
+				"delims.txt": {Data: []byte("{{.Name}} demo"), Mode: 0644},
+			},
+		},
+		{
+			name: "conflict",
+			files: map[string]string{
+				"app/config.txt": "---\nconflict: skip\n---\ngenerated",
+				"app/main.txt":   "generated",
+			},
+			existing: map[string]string{
+				"out/config.txt": "local",
+				"out/main.txt":   "local",
+			},
+			expectedFiles: map[string]templit.MemFile{
+				"config.txt": {Data: []byte("local"), Mode: 0644},
+				"main.txt":   {Data: []byte("generated"), Mode: 0644},
+			},
+		},
+		{
+			name: "not front matter",
+			files: map[string]string{
+				"app/deploy.yaml": "---\nkind: Service\n---\nname: {{.Name}}\n",
+			},
+			expectedFiles: map[string]templit.MemFile{
+				"deploy.yaml": {Data: []byte("---\nkind: Service\n---\nname: demo\n"), Mode: 0644},
+			},
+		},
+		{
+			name: "misspelled field",
+			files: map[string]string{
+				"app/config.txt": "---\nmode: \"0600\"\nconflcit: skip\n---\ngenerated",
+			},
+			expectedError: "error walking through directory: error parsing template: app/config.txt: invalid front matter: yaml: unmarshal errors:\n  line 2: field conflcit not found in type templit.FrontMatter",
+		},
+		{
+			name: "invalid field value",
+			files: map[string]string{
+				"app/config.txt": "---\nraw: [true]\n---\ngenerated",
+			},
+			expectedError: "error walking through directory: error parsing template: app/config.txt: invalid front matter: yaml: unmarshal errors:\n  line 1: cannot unmarshal !!seq into bool",
+		},
+		{
+			name: "path outside of the output directory",
+			files: map[string]string{
+				"app/escape.txt": "---\npath: ../escape.txt\n---\nescape",
+			},
+			expectedError: `error walking through directory: front matter path "../escape.txt" is outside of the output directory`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for name, content := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte(content), Mode: 0644}
+			}
+
+			out := templit.NewMemOutput()
+			out.Root = "out"
+			for name, content := range tt.existing {
+				if err := out.WriteFile(name, []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+
+			executor := templit.NewExecutor(nil, templit.WithOutput(out))
+			err := executor.WalkAndProcessFS(fsys, "app", "out", map[string]string{"Name": "demo"})
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to process fs: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expectedFiles, memFileEntries(out)); diff != "" {
+				t.Errorf("files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package templit
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+)
+
+// Rewrite replaces the InsteadOf prefix of repository URLs with URL, like git's url.<base>.insteadOf.
+// Prefixes are matched against host/owner/repo, with or without the scheme and user.
+type Rewrite struct {
+	URL       string `json:"url"`
+	InsteadOf string `json:"instead_of"`
+}
+
+// GitConfig is the configuration of a DefaultGitClient.
+type GitConfig struct {
+	// Credentials are keyed by host, optionally followed by an owner or repository prefix.
+	Credentials map[string]Credential `json:"credentials"`
+	// Rewrites are applied to repository URLs before they are cloned.
+	Rewrites []Rewrite `json:"rewrites"`
+}
+
+// LoadGitConfig reads a JSON encoded GitConfig from path.
+func LoadGitConfig(path string) (*GitConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git config: %w", err)
+	}
+
+	var cfg GitConfig
+	if err := json.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse git config %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Configure adds the credentials and rewrites of cfg to the client.
+// Credentials in cfg replace existing credentials for the same key.
+func (d *DefaultGitClient) Configure(cfg *GitConfig) {
+	if d.Credentials == nil {
+		d.Credentials = map[string]Credential{}
+	}
+
+	maps.Copy(d.Credentials, cfg.Credentials)
+	d.Rewrites = append(d.Rewrites, cfg.Rewrites...)
+}
@@ -0,0 +1,91 @@
+package templit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestLoadGitConfig tests the LoadGitConfig function.
+func TestLoadGitConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *templit.GitConfig
+		wantErr  bool
+	}{
+		{
+			name: "credentials and rewrites",
+			content: `{
+				"credentials": {
+					"github.com": {"method": "token", "token_env": "GITHUB_TOKEN"},
+					"git.internal/platform": {"method": "ssh-key", "username": "deploy", "key_file": "~/.ssh/id_ed25519"}
+				},
+				"rewrites": [{"url": "git.internal/mirror/", "instead_of": "github.com/org/"}]
+			}`,
+			expected: &templit.GitConfig{
+				Credentials: map[string]templit.Credential{
+					"github.com":            {Method: templit.AuthToken, TokenEnv: "GITHUB_TOKEN"},
+					"git.internal/platform": {Method: templit.AuthSSHKey, Username: "deploy", KeyFile: "~/.ssh/id_ed25519"},
+				},
+				Rewrites: []templit.Rewrite{
+					{URL: "git.internal/mirror/", InsteadOf: "github.com/org/"},
+				},
+			},
+		},
+		{
+			name:    "invalid json",
+			content: `{"credentials": [}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "git.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			cfg, err := templit.LoadGitConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tt.expected, cfg); diff != "" {
+				t.Errorf("config mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestDefaultGitClient_Configure tests that a token read from the environment is only used for its host.
+func TestDefaultGitClient_Configure(t *testing.T) {
+	t.Setenv("TEMPLIT_TEST_TOKEN", "secret")
+
+	client := templit.NewDefaultGitClient("main", "")
+	client.Configure(&templit.GitConfig{
+		Credentials: map[string]templit.Credential{
+			"github.com": {Method: templit.AuthToken, TokenEnv: "TEMPLIT_TEST_TOKEN"},
+		},
+	})
+
+	_, auth, err := client.Endpoint("github.com", "owner", "repo")
+	if err != nil {
+		t.Fatalf("failed to get endpoint: %v", err)
+	}
+	if auth == nil || auth.String() != "http-basic-auth - username:*******" {
+		t.Errorf("expected token auth for github.com, got %v", auth)
+	}
+
+	_, auth, err = client.Endpoint("example.com", "owner", "repo")
+	if err != nil {
+		t.Fatalf("failed to get endpoint: %v", err)
+	}
+	if auth != nil {
+		t.Errorf("expected no auth for example.com, got %v", auth)
+	}
+}
@@ -0,0 +1,59 @@
+package templit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// IgnoreFileName is the name of the files listing template paths, in gitignore syntax, that are neither parsed nor
+// generated. Patterns apply to the directory of the file and its subdirectories.
+const IgnoreFileName = ".templitignore"
+
+// ignorer collects the patterns of the ignore files of a template tree while it is walked top down.
+type ignorer struct {
+	patterns []gitignore.Pattern
+}
+
+// load adds the patterns of the ignore file in the directory dir of fsys, if there is one.
+// rel is the slash separated path of dir relative to the template root.
+func (ig *ignorer) load(fsys fs.FS, dir, rel string) error {
+	content, err := fs.ReadFile(fsys, path.Join(dir, IgnoreFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+	}
+
+	var domain []string
+	if rel != "." {
+		domain = strings.Split(rel, "/")
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// patterns of nested files are added later and take precedence
+		ig.patterns = append(ig.patterns, gitignore.ParsePattern(line, domain))
+	}
+
+	return nil
+}
+
+// ignored reports whether the path rel, relative to the template root, is ignored.
+// Ignore files and the spec and schema files at the root are always ignored.
+func (ig *ignorer) ignored(rel string, isDir bool) bool {
+	if path.Base(rel) == IgnoreFileName || rel == SpecFileName || rel == SchemaFileName {
+		return true
+	}
+
+	return gitignore.NewMatcher(ig.patterns).Match(strings.Split(rel, "/"), isDir)
+}
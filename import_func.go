@@ -2,7 +2,8 @@ package templit
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
+	"path"
 	"path/filepath"
 )
 
@@ -15,6 +16,7 @@ import (
 //     ```
 //     {{ import "<host>/<owner>/<repo>/<path>@<tag_or_hash_or_branch>" "<path_to_genrate_files>" . }}
 //     {{ import "<host>/<owner>/<repo>/<path>#<block>@<tag_or_hash_or_branch>" "<path_to_genrate_files>" . }}
+//     {{ import "file://<dir>//<path>@<tag_or_hash_or_branch>" "<path_to_genrate_files>" . }}
 //     ```
 //
 // Placeholders:
@@ -22,61 +24,60 @@ import (
 //   - `<owner>`: Repository owner or organization.
 //   - `<repo>`: Repository name.
 //   - `<path>`: Path to the desired file or directory within the repository.
+//   - `<dir>`: Local directory, optionally inside a git repository. Relative paths are resolved against the working directory.
 //   - `<tag_or_hash_or_branch>`: Specific Git reference (tag, commit hash, or branch name).
 func (e *Executor) ImportFunc(outputDir string) func(repoAndTag, destPath string, data interface{}) (string, error) {
 	return func(repoAndTag, destPath string, data interface{}) (string, error) {
-		const tempDirPrefix = "temp_clone_"
-
 		depInfo, err := ParseDepURL(repoAndTag)
 		if err != nil {
 			return "", fmt.Errorf("failed to parse embed URL: %w", err)
 		}
 
-		tempDir, err := os.MkdirTemp("", tempDirPrefix)
-		if err != nil {
-			return "", fmt.Errorf("failed to create temp dir: %w", err)
-		}
-		defer os.RemoveAll(tempDir) // Cleanup
-
-		if err := e.git.Clone(depInfo.Host, depInfo.Owner, depInfo.Repo, tempDir); err != nil {
-			return "", fmt.Errorf("failed to clone repo: %w", err)
+		if err := e.importDep(depInfo, outputDir, destPath, data); err != nil {
+			return "", err
 		}
 
-		if depInfo.Tag != "" && depInfo.Tag != e.git.DefaultBranch() {
-			err = e.git.Checkout(tempDir, depInfo.Tag)
-			if err != nil {
-				return "", fmt.Errorf("failed to checkout ref %s: %w", depInfo.Tag, err)
-			}
-		}
-
-		sourcePath := filepath.Join(tempDir, depInfo.Path)
-		outputPath := filepath.Join(outputDir, destPath)
+		return "", nil
+	}
+}
 
-		// check if path is a file
-		if info, err := os.Stat(sourcePath); err == nil && !info.IsDir() {
-			// parse the file
-			if err := e.ParsePath(filepath.Dir(sourcePath)); err != nil {
-				return "", fmt.Errorf("failed to create executor: %w", err)
-			}
+// importDep renders the file or directory of dep into destPath below outputDir.
+func (e *Executor) importDep(depInfo *DepInfo, outputDir, destPath string, data interface{}) error {
+	co, err := e.checkoutDep(depInfo)
+	if err != nil {
+		return err
+	}
+	defer co.cleanup()
 
-			// render the file
-			string, err := e.Render(sourcePath, data)
-			if err != nil {
-				return "", fmt.Errorf("failed to render template: %w", err)
-			}
+	return e.importCheckout(co, depInfo, outputDir, destPath, data)
+}
 
-			// write the file
-			if err := os.WriteFile(filepath.Join(outputPath, filepath.Base(depInfo.Path)), []byte(string), 0644); err != nil {
-				return "", fmt.Errorf("failed to write file: %w", err)
-			}
+// importCheckout renders the path of depInfo in the checkout co into destPath below outputDir.
+func (e *Executor) importCheckout(co *checkout, depInfo *DepInfo, outputDir, destPath string, data interface{}) error {
+	sourcePath := co.path(depInfo.Path)
+	outputPath := filepath.Join(outputDir, destPath)
+	e.setOutputRoot(outputDir)
 
-			return "", nil
+	// check if path is a file
+	if info, err := fs.Stat(co, sourcePath); err == nil && !info.IsDir() {
+		// parse the file
+		if err := e.parseFS(co, path.Dir(sourcePath), co.name); err != nil {
+			return fmt.Errorf("failed to create executor: %w", err)
 		}
 
-		if err := e.WalkAndProcessDir(sourcePath, outputPath, data); err != nil {
-			return "", fmt.Errorf("failed to process template: %w", err)
+		// render and write the file
+		filePath := filepath.Join(outputPath, filepath.Base(depInfo.Path))
+		e.setSource(filePath, templateName(co.name, sourcePath))
+		if err := e.processFile(&walker{fsys: co, prefix: co.name, root: outputDir, base: path.Dir(sourcePath)}, sourcePath, filePath, 0644, data); err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
 		}
 
-		return "", nil
+		return nil
 	}
+
+	if err := e.walkFS(&walker{fsys: co, prefix: co.name, root: outputDir}, sourcePath, outputPath, data); err != nil {
+		return fmt.Errorf("failed to process template: %w", err)
+	}
+
+	return nil
 }
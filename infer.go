@@ -0,0 +1,289 @@
+package templit
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"text/template/parse"
+)
+
+// InferVariables returns the variables of the template tree below the directory root of fsys. The variables declared
+// by its SpecFileName come first, followed by every other top-level data field referenced by the templates, their
+// front matter and the file and directory names, in order of first use. The type of an undeclared field is inferred
+// from its use: conditions are bools, ranges are lists, fields with subfields are maps, fields compared with numbers
+// are ints and all other fields are strings.
+func (e *Executor) InferVariables(fsys fs.FS, root string) ([]Variable, error) {
+	spec, err := LoadSpec(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &fieldScanner{types: map[string]VariableType{}}
+	var ignore ignorer
+	err = fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk directory: %w", err)
+		}
+
+		rel := relPath(root, name)
+		if rel != "." && (ignore.ignored(rel, d.IsDir()) || (d.IsDir() && d.Name() == ".git")) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if rel != "." {
+			// the data of an entry whose name ranges over a list is the element, not the top-level data
+			loop, err := s.scan(name, d.Name(), e.leftDelim, e.rightDelim)
+			if err != nil || loop {
+				if d.IsDir() && err == nil {
+					return fs.SkipDir
+				}
+				return err
+			}
+		}
+
+		if d.IsDir() {
+			return ignore.load(fsys, name, rel)
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		if e.isCopyOnly(rel, content) {
+			return nil
+		}
+
+		fm, body, err := parseFrontMatter(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		left, right := e.delims(fm)
+		if fm != nil {
+			for _, value := range []string{fm.Skip, fm.Path} {
+				if _, err := s.scan(name, value, left, right); err != nil {
+					return err
+				}
+			}
+
+			if fm.Raw {
+				return nil
+			}
+		}
+
+		_, err = s.scan(name, string(body), left, right)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var variables []Variable
+	declared := map[string]bool{}
+	if spec != nil {
+		for _, v := range spec.Variables {
+			variables = append(variables, v)
+			declared[v.Name] = true
+		}
+	}
+
+	for _, name := range s.order {
+		if declared[name] {
+			continue
+		}
+
+		typ := s.types[name]
+		if typ == "" {
+			typ = VariableString
+		}
+
+		variables = append(variables, Variable{Name: name, Type: typ})
+	}
+
+	return variables, nil
+}
+
+// InferDepVariables is InferVariables for the template tree of the dependency dep, such as
+// "github.com/owner/repo/path@ref".
+func (e *Executor) InferDepVariables(dep string) ([]Variable, error) {
+	depInfo, err := ParseDepURL(dep)
+	if err != nil {
+		return nil, err
+	}
+
+	if depInfo.Tag == "" && !depInfo.IsLocal() {
+		depInfo.Tag = e.git.DefaultBranch()
+	}
+
+	co, err := e.checkoutDep(depInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer co.cleanup()
+
+	return e.InferVariables(co, co.path(depInfo.Path))
+}
+
+// fieldScanner collects the top-level data fields referenced by templates.
+type fieldScanner struct {
+	order []string
+	types map[string]VariableType
+}
+
+// scan parses text with the delimiters left and right and collects its fields. name is used in errors.
+// It reports whether text ranges over a list at the top level, which makes it a name expanded per element.
+func (s *fieldScanner) scan(name, text, left, right string) (bool, error) {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+
+	trees := map[string]*parse.Tree{}
+	if _, err := tree.Parse(text, left, right, trees); err != nil {
+		return false, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var loop bool
+	if tree.Root != nil {
+		for _, node := range tree.Root.Nodes {
+			if r, ok := node.(*parse.RangeNode); ok && r.ElseList == nil {
+				loop = true
+			}
+		}
+	}
+
+	// defined templates are assumed to be called with the top-level data, they are scanned after the main template
+	names := make([]string, 0, len(trees))
+	for treeName := range trees {
+		if treeName != name {
+			names = append(names, treeName)
+		}
+	}
+	sort.Strings(names)
+
+	for _, treeName := range append([]string{name}, names...) {
+		if t, ok := trees[treeName]; ok && t.Root != nil {
+			s.node(t.Root, true)
+		}
+	}
+
+	return loop, nil
+}
+
+// add records the use of the top-level field name with the type typ, which may be empty if it is unknown.
+func (s *fieldScanner) add(name string, typ VariableType) {
+	current, seen := s.types[name]
+	if !seen {
+		s.order = append(s.order, name)
+	}
+	if current == "" {
+		s.types[name] = typ
+	}
+}
+
+// node collects the fields of node. root reports whether dot is the top-level data.
+func (s *fieldScanner) node(node parse.Node, root bool) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, n := range node.Nodes {
+			s.node(n, root)
+		}
+	case *parse.ActionNode:
+		s.pipe(node.Pipe, root, "")
+	case *parse.IfNode:
+		s.pipe(node.Pipe, root, VariableBool)
+		s.node(node.List, root)
+		s.node(node.ElseList, root)
+	case *parse.RangeNode:
+		s.pipe(node.Pipe, root, VariableList)
+		s.node(node.List, false)
+		s.node(node.ElseList, root)
+	case *parse.WithNode:
+		s.pipe(node.Pipe, root, "")
+		s.node(node.List, false)
+		s.node(node.ElseList, root)
+	case *parse.TemplateNode:
+		s.pipe(node.Pipe, root, "")
+	}
+}
+
+// pipe collects the fields of pipe. A pipe consisting of a single field has the type typ.
+func (s *fieldScanner) pipe(pipe *parse.PipeNode, root bool, typ VariableType) {
+	if pipe == nil {
+		return
+	}
+
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		typ = ""
+	}
+
+	for _, cmd := range pipe.Cmds {
+		s.command(cmd, root, typ)
+	}
+}
+
+// command collects the fields of cmd, inferring the types of the arguments of logic and comparison functions.
+func (s *fieldScanner) command(cmd *parse.CommandNode, root bool, typ VariableType) {
+	if len(cmd.Args) == 0 {
+		return
+	}
+
+	argType := typ
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		switch ident.Ident {
+		case "not", "and", "or":
+			argType = VariableBool
+		case "eq", "ne", "lt", "le", "gt", "ge":
+			argType = VariableString
+			for _, arg := range cmd.Args[1:] {
+				if _, ok := arg.(*parse.NumberNode); ok {
+					argType = VariableInt
+				}
+			}
+		default:
+			argType = ""
+		}
+	}
+
+	for _, arg := range cmd.Args {
+		s.arg(arg, root, argType)
+	}
+}
+
+// arg collects the fields of a command argument of the type typ.
+func (s *fieldScanner) arg(arg parse.Node, root bool, typ VariableType) {
+	switch arg := arg.(type) {
+	case *parse.FieldNode:
+		if root {
+			s.field(arg.Ident, typ)
+		}
+	case *parse.VariableNode:
+		// $ is the top-level data everywhere
+		if len(arg.Ident) > 1 && arg.Ident[0] == "$" {
+			s.field(arg.Ident[1:], typ)
+		}
+	case *parse.ChainNode:
+		if _, ok := arg.Node.(*parse.DotNode); ok && root {
+			s.field(arg.Field, typ)
+		}
+		if pipe, ok := arg.Node.(*parse.PipeNode); ok {
+			s.pipe(pipe, root, "")
+		}
+	case *parse.PipeNode:
+		s.pipe(arg, root, typ)
+	}
+}
+
+// field records the top-level field of the field chain idents, which is a map if it has subfields.
+func (s *fieldScanner) field(idents []string, typ VariableType) {
+	if len(idents) > 1 {
+		typ = VariableMap
+	}
+
+	s.add(idents[0], typ)
+}
@@ -0,0 +1,51 @@
+package templit_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestExecutor_InferVariables tests finding the top-level fields templates use and inferring their types.
+func TestExecutor_InferVariables(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/templit.yaml": {Data: []byte("variables:\n  - name: Name\n    description: project name\n    required: true\n")},
+		"app/{{.Name}}.txt": {Data: []byte(`{{if .Docker}}{{.Image}}{{end}}
+{{range .Services}}{{.Name}} {{$.Owner.Email}}{{end}}
+{{with .License}}{{.Name}}{{end}}
+{{if and (not .Private) (eq .Replicas 3)}}{{.Name | printf "%s"}}{{end}}
+{{define "footer"}}{{.Footer}}{{end}}`)},
+		"app/{{range .Modules}}{{.}}{{end}}/go.mod": {Data: []byte("module {{.Path}}")},
+		"app/docs.md":        {Data: []byte("---\nskip: \"{{not .Docs}}\"\n---\n{{.Title}}")},
+		"app/raw.txt":        {Data: []byte("---\nraw: true\n---\n{{.Raw}}")},
+		"app/.templitignore": {Data: []byte("fixtures/\n")},
+		"app/fixtures/x.txt": {Data: []byte("{{.Fixture}}")},
+	}
+
+	executor := templit.NewExecutor(nil)
+	variables, err := executor.InferVariables(fsys, "app")
+	if err != nil {
+		t.Fatalf("failed to infer variables: %v", err)
+	}
+
+	expected := []templit.Variable{
+		{Name: "Name", Description: "project name", Required: true},
+		{Name: "Docs", Type: templit.VariableBool},
+		{Name: "Title", Type: templit.VariableString},
+		{Name: "Docker", Type: templit.VariableBool},
+		{Name: "Image", Type: templit.VariableString},
+		{Name: "Services", Type: templit.VariableList},
+		{Name: "Owner", Type: templit.VariableMap},
+		{Name: "License", Type: templit.VariableString},
+		{Name: "Private", Type: templit.VariableBool},
+		{Name: "Replicas", Type: templit.VariableInt},
+		{Name: "Footer", Type: templit.VariableString},
+		{Name: "Modules", Type: templit.VariableList},
+	}
+
+	if diff := cmp.Diff(expected, variables); diff != "" {
+		t.Errorf("variables mismatch (-want +got):\n%s", diff)
+	}
+}
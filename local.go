@@ -0,0 +1,163 @@
+package templit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// copyLocalRef copies the content of the local directory root at ref to dest and returns the resolved commit.
+// root must be inside a git repository.
+func copyLocalRef(root, ref string, dest billy.Filesystem) (string, error) {
+	r, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository at %s: %w", root, err)
+	}
+
+	w, err := r.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to open worktree of %s: %w", root, err)
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(w.Filesystem.Root(), absRoot)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve reference %s: %w", ref, err)
+	}
+
+	commit, err := r.CommitObject(*hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to read tree of %s: %w", hash, err)
+	}
+
+	if rel != "." {
+		if tree, err = tree.Tree(filepath.ToSlash(rel)); err != nil {
+			return "", fmt.Errorf("failed to find %s at %s: %w", rel, ref, err)
+		}
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		mode, err := f.Mode.ToOSFileMode()
+		if err != nil {
+			return err
+		}
+
+		if !mode.IsRegular() {
+			return nil
+		}
+
+		reader, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		if err := dest.MkdirAll(path.Dir(f.Name), 0755); err != nil {
+			return err
+		}
+
+		out, err := dest.OpenFile(f.Name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(out, reader); err != nil {
+			out.Close()
+			return err
+		}
+
+		return out.Close()
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy %s at %s: %w", root, ref, err)
+	}
+
+	return hash.String(), nil
+}
+
+// copyTree copies the regular files and directories in src to dst, skipping .git directories.
+func copyTree(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return errors.New("not a directory")
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return writeFileFrom(target, f, info.Mode().Perm())
+	})
+}
+
+// writeFileFrom writes the content of r to the file at path.
+func writeFileFrom(path string, r io.Reader, perm fs.FileMode) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
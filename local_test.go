@@ -0,0 +1,121 @@
+package templit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/euforic/templit"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-cmp/cmp"
+)
+
+// commitFile writes content to name in the repository at dir and commits it.
+func commitFile(t *testing.T, r *git.Repository, dir, name, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree: %v", err)
+	}
+
+	if _, err := w.Add(name); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+
+	_, err = w.Commit("update "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+}
+
+// TestEmbedFunc_Local tests embedding templates from local directories.
+func TestEmbedFunc_Local(t *testing.T) {
+	repoDir := t.TempDir()
+
+	r, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "sub/greeting.txt", "Hello v1, {{.Name}}!")
+
+	head, err := r.Head()
+	if err != nil {
+		t.Fatalf("failed to read head: %v", err)
+	}
+
+	if _, err := r.CreateTag("v1", head.Hash(), nil); err != nil {
+		t.Fatalf("failed to tag: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "sub/greeting.txt", "Hello v2, {{.Name}}!")
+
+	if err := os.WriteFile(filepath.Join(repoDir, "sub/greeting.txt"), []byte("Hello wip, {{.Name}}!"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		remotePath   string
+		expectedText string
+		wantErr      bool
+	}{
+		{
+			name:         "relative path",
+			remotePath:   "./test_data/templates/basic_test/greeting.txt",
+			expectedText: "Hello, John!\n",
+		},
+		{
+			name:         "file url with root separator",
+			remotePath:   "file://test_data/templates//basic_test/greeting.txt",
+			expectedText: "Hello, John!\n",
+		},
+		{
+			name:         "working tree",
+			remotePath:   "file://" + repoDir + "//sub/greeting.txt",
+			expectedText: "Hello wip, John!",
+		},
+		{
+			name:         "git tag",
+			remotePath:   "file://" + repoDir + "//sub/greeting.txt@v1",
+			expectedText: "Hello v1, John!",
+		},
+		{
+			name:         "git tag of subdirectory",
+			remotePath:   "file://" + repoDir + "/sub/greeting.txt@v1",
+			expectedText: "Hello v1, John!",
+		},
+		{
+			name:       "unknown ref",
+			remotePath: "file://" + repoDir + "//sub/greeting.txt@v9",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := templit.NewExecutor(templit.NewDefaultGitClient("main", ""))
+			result, err := executor.EmbedFunc(tt.remotePath, map[string]string{"Name": "John"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tt.expectedText, result); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
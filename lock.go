@@ -0,0 +1,124 @@
+package templit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// LockfileName is the default name of the lockfile.
+const LockfileName = "templit.lock"
+
+// LockEntry pins an embed or import reference to a resolved commit.
+type LockEntry struct {
+	Host   string `json:"host"`
+	Owner  string `json:"owner"`
+	Repo   string `json:"repo"`
+	Path   string `json:"path,omitempty"`
+	Ref    string `json:"ref"`
+	Commit string `json:"commit"`
+	Hash   string `json:"hash"`
+}
+
+// Lockfile records every dependency resolved during generation so later runs can reproduce it.
+type Lockfile struct {
+	Deps []LockEntry `json:"deps"`
+
+	mu      sync.Mutex
+	changed bool
+}
+
+// LoadLockfile reads the lockfile at path. A missing file results in an empty lockfile.
+func LoadLockfile(path string) (*Lockfile, error) {
+	lock := &Lockfile{}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+
+	if err := json.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+
+	return lock, nil
+}
+
+// Save writes the lockfile to path.
+func (l *Lockfile) Save(path string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sort.Slice(l.Deps, func(i, j int) bool {
+		return l.Deps[i].key() < l.Deps[j].key()
+	})
+
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+
+	l.changed = false
+
+	return nil
+}
+
+// Changed reports whether entries were recorded since the lockfile was loaded or saved.
+func (l *Lockfile) Changed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.changed
+}
+
+// Lookup returns the entry pinning the given dependency at ref.
+func (l *Lockfile) Lookup(dep DepInfo, ref string) (LockEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := LockEntry{Host: dep.Host, Owner: dep.Owner, Repo: dep.Repo, Path: dep.Path, Ref: ref}.key()
+	for _, entry := range l.Deps {
+		if entry.key() == key {
+			return entry, true
+		}
+	}
+
+	return LockEntry{}, false
+}
+
+// Record adds or replaces the entry for a dependency.
+func (l *Lockfile) Record(entry LockEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, existing := range l.Deps {
+		if existing.key() != entry.key() {
+			continue
+		}
+
+		if existing != entry {
+			l.Deps[i] = entry
+			l.changed = true
+		}
+
+		return
+	}
+
+	l.Deps = append(l.Deps, entry)
+	l.changed = true
+}
+
+// key identifies the dependency reference an entry pins.
+func (e LockEntry) key() string {
+	return e.Host + "/" + e.Owner + "/" + e.Repo + "/" + e.Path + "@" + e.Ref
+}
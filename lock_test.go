@@ -0,0 +1,115 @@
+package templit_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// TestLockfile_SaveLoad tests that a saved lockfile can be loaded again.
+func TestLockfile_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), templit.LockfileName)
+
+	lock, err := templit.LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("failed to load missing lockfile: %v", err)
+	}
+
+	entry := templit.LockEntry{
+		Host:   "github.com",
+		Owner:  "owner",
+		Repo:   "repo",
+		Path:   "greeting.txt",
+		Ref:    "main",
+		Commit: "0123456789abcdef0123456789abcdef01234567",
+		Hash:   "abc",
+	}
+	lock.Record(entry)
+
+	if !lock.Changed() {
+		t.Fatalf("expected lockfile to be changed")
+	}
+
+	if err := lock.Save(path); err != nil {
+		t.Fatalf("failed to save lockfile: %v", err)
+	}
+
+	loaded, err := templit.LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("failed to load lockfile: %v", err)
+	}
+
+	got, ok := loaded.Lookup(templit.DepInfo{Host: "github.com", Owner: "owner", Repo: "repo", Path: "greeting.txt"}, "main")
+	if !ok {
+		t.Fatalf("expected entry to be found")
+	}
+
+	if diff := cmp.Diff(entry, got); diff != "" {
+		t.Errorf("entry mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestEmbedFunc_Lockfile tests that EmbedFunc records and verifies dependencies in a lockfile.
+func TestEmbedFunc_Lockfile(t *testing.T) {
+	const remotePath = "https://test_data/templates/basic_test/greeting.txt@main"
+
+	tests := []struct {
+		name          string
+		locked        []templit.LockEntry
+		update        bool
+		expectedError string
+	}{
+		{
+			name: "records new dependency",
+		},
+		{
+			name: "rejects changed content",
+			locked: []templit.LockEntry{
+				{Host: "test_data", Owner: "templates", Repo: "basic_test", Path: "greeting.txt", Ref: "main", Commit: "abc", Hash: "def"},
+			},
+			expectedError: "does not match lockfile",
+		},
+		{
+			name: "update ignores pinned dependency",
+			locked: []templit.LockEntry{
+				{Host: "test_data", Owner: "templates", Repo: "basic_test", Path: "greeting.txt", Ref: "main", Commit: "abc", Hash: "def"},
+			},
+			update: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock := &templit.Lockfile{Deps: tt.locked}
+			executor := templit.NewExecutor(&MockGitClient{}, templit.WithLockfile(lock, tt.update))
+
+			_, err := executor.EmbedFunc(remotePath, map[string]string{"Name": "John"})
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to embed: %v", err)
+			}
+
+			entry, ok := lock.Lookup(templit.DepInfo{Host: "test_data", Owner: "templates", Repo: "basic_test", Path: "greeting.txt"}, "main")
+			if !ok {
+				t.Fatalf("expected dependency to be recorded")
+			}
+
+			if diff := cmp.Diff(templit.LockEntry{Host: "test_data", Owner: "templates", Repo: "basic_test", Path: "greeting.txt", Ref: "main"}, entry, cmpopts.IgnoreFields(templit.LockEntry{}, "Commit", "Hash")); diff != "" {
+				t.Errorf("entry mismatch (-want +got):\n%s", diff)
+			}
+
+			if entry.Commit == "" || entry.Hash == "" {
+				t.Errorf("expected commit and hash to be recorded, got %+v", entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package templit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+)
+
+// ManifestName is the name of the manifest written into generated projects.
+const ManifestName = ".templit.json"
+
+// Version is the templit version recorded in manifests. It defaults to the module version from the build info.
+var Version = moduleVersion()
+
+// Manifest records how a project was generated so it can be updated, checked for drift and cleaned up later.
+type Manifest struct {
+	// Source is the template the project was generated from, as a DepInfo string including its ref.
+	Source string `json:"source"`
+	// Commit is the commit Source resolved to, if it is a git repository.
+	Commit string `json:"commit,omitempty"`
+	// Data is the data the templates were rendered with.
+	Data map[string]interface{} `json:"data,omitempty"`
+	// Version is the templit version that generated the project.
+	Version string `json:"version"`
+	// Files maps the slash separated paths of the generated files, relative to the output directory, to the sha256 hash of their content.
+	Files map[string]string `json:"files"`
+
+	mu sync.Mutex
+}
+
+// NewManifest creates an empty manifest for a project generated from source by this templit version.
+func NewManifest(source string) *Manifest {
+	return &Manifest{
+		Source:  source,
+		Version: Version,
+		Files:   map[string]string{},
+	}
+}
+
+// WithManifest makes the executor record every generated file in manifest.
+func WithManifest(manifest *Manifest) ExecutorOption {
+	return func(e *Executor) {
+		e.manifest = manifest
+	}
+}
+
+// Record records the generated file at the slash separated path rel with the given content.
+func (m *Manifest) Record(rel string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Files == nil {
+		m.Files = map[string]string{}
+	}
+
+	m.Files[rel] = hashContent(data)
+}
+
+// hashContent returns the hex encoded sha256 hash of data.
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Reset forgets all recorded files.
+func (m *Manifest) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Files = map[string]string{}
+}
+
+// LoadManifest reads the manifest at path.
+func LoadManifest(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return parseManifest(content, path)
+}
+
+// parseManifest decodes the content of the manifest at path.
+func parseManifest(content []byte, path string) (*Manifest, error) {
+	manifest := &Manifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+
+	return manifest, nil
+}
+
+// Save writes the manifest to path.
+func (m *Manifest) Save(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// recordFile records a generated file in the manifest of the executor, if any.
+// filePath is made relative to the output directory root.
+func (e *Executor) recordFile(root, filePath string, data []byte) {
+	if e.manifest == nil {
+		return
+	}
+
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil {
+		rel = filePath
+	}
+
+	e.manifest.Record(filepath.ToSlash(rel), data)
+}
+
+// moduleVersion returns the version of the templit module from the build info.
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+
+	if info.Main.Path == "github.com/euforic/templit" {
+		return info.Main.Version
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/euforic/templit" {
+			return dep.Version
+		}
+	}
+
+	return "devel"
+}
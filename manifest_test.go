@@ -0,0 +1,75 @@
+package templit_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/euforic/templit"
+	git "github.com/go-git/go-git/v5"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// TestManifest tests recording the source, commit and generated files of a project.
+func TestManifest(t *testing.T) {
+	repoDir := t.TempDir()
+
+	r, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "app/{{.Name}}/main.txt", "Hello, {{.Name}}!")
+	commitFile(t, r, repoDir, "app/README.md", "{{.Name}}")
+
+	head, err := r.Head()
+	if err != nil {
+		t.Fatalf("failed to read head: %v", err)
+	}
+	if _, err := r.CreateTag("v1", head.Hash(), nil); err != nil {
+		t.Fatalf("failed to tag: %v", err)
+	}
+
+	source := "file://" + repoDir + "//app@v1"
+	manifest := templit.NewManifest(source)
+	manifest.Data = map[string]interface{}{"Name": "demo"}
+
+	executor := templit.NewExecutor(templit.NewMemGitClient("main", ""),
+		templit.WithOutput(templit.NewMemOutput()),
+		templit.WithManifest(manifest),
+	)
+
+	if _, err := executor.ImportFunc("out")(source, ".", manifest.Data); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	expected := &templit.Manifest{
+		Source:  source,
+		Commit:  head.Hash().String(),
+		Data:    map[string]interface{}{"Name": "demo"},
+		Version: templit.Version,
+		Files: map[string]string{
+			// sha256 of "demo" and "Hello, demo!"
+			"README.md":     "2a97516c354b68848cdbd8f54a226a0a55b21ed138e207ad6c5cbb9c00aa5aea",
+			"demo/main.txt": "8a23547aaaafe41e518787812e51ad3ba2032f9db35d6bcc6baa678ce2f365bd",
+		},
+	}
+
+	if diff := cmp.Diff(expected, manifest, cmpopts.IgnoreFields(templit.Manifest{}, "mu")); diff != "" {
+		t.Errorf("manifest mismatch (-want +got):\n%s", diff)
+	}
+
+	path := filepath.Join(t.TempDir(), templit.ManifestName)
+	if err := manifest.Save(path); err != nil {
+		t.Fatalf("failed to save manifest: %v", err)
+	}
+
+	loaded, err := templit.LoadManifest(path)
+	if err != nil {
+		t.Fatalf("failed to load manifest: %v", err)
+	}
+
+	if diff := cmp.Diff(manifest, loaded, cmpopts.IgnoreFields(templit.Manifest{}, "mu")); diff != "" {
+		t.Errorf("loaded manifest mismatch (-want +got):\n%s", diff)
+	}
+}
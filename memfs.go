@@ -0,0 +1,185 @@
+package templit
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// FSGitClient is a GitClient that can check out repositories into an fs.FS instead of a directory on disk.
+// The executor uses CloneFS for embeds and imports when no cache is configured.
+type FSGitClient interface {
+	GitClient
+	// CloneFS checks out ref, or the default branch when ref is empty, and returns its worktree and commit.
+	CloneFS(host, owner, repo, ref string) (fs.FS, string, error)
+}
+
+// MemGitClient is a GitClient that clones repositories into memory, so no writable disk is needed.
+// It is configured like the DefaultGitClient it embeds, which is used when cloning to disk.
+type MemGitClient struct {
+	*DefaultGitClient
+}
+
+// NewMemGitClient creates a new MemGitClient with the given default branch and token.
+func NewMemGitClient(defaultBranch string, token string) *MemGitClient {
+	return &MemGitClient{
+		DefaultGitClient: NewDefaultGitClient(defaultBranch, token),
+	}
+}
+
+// CloneFS clones ref into memory and returns its worktree and the commit it resolved to.
+// Local dependencies without a ref are read directly from disk.
+func (m *MemGitClient) CloneFS(host, owner, repo, ref string) (fs.FS, string, error) {
+	if host == "file://" {
+		if ref == "" {
+			return os.DirFS(repo), "", nil
+		}
+
+		worktree := memfs.New()
+		commit, err := copyLocalRef(repo, ref, worktree)
+		if err != nil {
+			return nil, "", err
+		}
+		return billyFS{worktree}, commit, nil
+	}
+
+	repoURL, auth, err := m.Endpoint(host, owner, repo)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var worktree billy.Filesystem
+	r, err := cloneRef(func(opts *git.CloneOptions) (*git.Repository, error) {
+		worktree = memfs.New()
+		return git.Clone(memory.NewStorage(), worktree, opts)
+	}, repoURL, auth, ref)
+	if err != nil {
+		return nil, "", err
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return billyFS{worktree}, head.Hash().String(), nil
+}
+
+// billyFS exposes a billy.Filesystem as a read-only fs.FS.
+type billyFS struct {
+	fs billy.Filesystem
+}
+
+// Open opens the named file or directory.
+func (b billyFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	info, err := b.fs.Stat(name)
+	if name == "." && err != nil {
+		// the root of an empty filesystem does not exist yet
+		return &billyDir{fs: b.fs, name: name}, nil
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	if info.IsDir() {
+		return &billyDir{fs: b.fs, name: name, info: info}, nil
+	}
+
+	f, err := b.fs.Open(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &billyFile{File: f, info: info}, nil
+}
+
+// billyFile is a regular file of a billyFS.
+type billyFile struct {
+	billy.File
+	info fs.FileInfo
+}
+
+// Stat returns the FileInfo of the file.
+func (f *billyFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+// billyDir is a directory of a billyFS.
+type billyDir struct {
+	fs      billy.Filesystem
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+// Stat returns the FileInfo of the directory.
+func (d *billyDir) Stat() (fs.FileInfo, error) {
+	if d.info == nil {
+		return rootInfo{}, nil
+	}
+	return d.info, nil
+}
+
+// Read fails because directories cannot be read.
+func (d *billyDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+// Close closes the directory.
+func (d *billyDir) Close() error {
+	return nil
+}
+
+// ReadDir reads the entries of the directory, see fs.ReadDirFile.
+func (d *billyDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		infos, err := d.fs.ReadDir(d.name)
+		if err != nil && d.info != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+
+		d.entries = make([]fs.DirEntry, 0, len(infos))
+		for _, info := range infos {
+			d.entries = append(d.entries, fs.FileInfoToDirEntry(info))
+		}
+	}
+
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+
+	return rest[:n], nil
+}
+
+// rootInfo is the FileInfo of the root of an empty billyFS.
+type rootInfo struct{}
+
+func (rootInfo) Name() string       { return "." }
+func (rootInfo) Size() int64        { return 0 }
+func (rootInfo) Mode() fs.FileMode  { return fs.ModeDir | 0755 }
+func (rootInfo) ModTime() time.Time { return time.Time{} }
+func (rootInfo) IsDir() bool        { return true }
+func (rootInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,136 @@
+package templit_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/euforic/templit"
+	git "github.com/go-git/go-git/v5"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestMemGitClient_CloneFS tests cloning branches, tags and commit hashes into memory.
+func TestMemGitClient_CloneFS(t *testing.T) {
+	base := t.TempDir()
+	repoDir := filepath.Join(base, "owner", "repo.git")
+
+	r, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "dir/greeting.txt", "v1")
+
+	first, err := r.Head()
+	if err != nil {
+		t.Fatalf("failed to read head: %v", err)
+	}
+
+	if _, err := r.CreateTag("v1", first.Hash(), nil); err != nil {
+		t.Fatalf("failed to tag: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "dir/greeting.txt", "v2")
+
+	second, err := r.Head()
+	if err != nil {
+		t.Fatalf("failed to read head: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		ref            string
+		expected       string
+		expectedCommit string
+		wantErr        bool
+	}{
+		{name: "default branch", ref: "", expected: "v2", expectedCommit: second.Hash().String()},
+		{name: "tag", ref: "v1", expected: "v1", expectedCommit: first.Hash().String()},
+		{name: "commit hash", ref: first.Hash().String(), expected: "v1", expectedCommit: first.Hash().String()},
+		{name: "unknown ref", ref: "unknown", wantErr: true},
+	}
+
+	client := templit.NewMemGitClient("master", "")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys, commit, err := client.CloneFS("file://"+base, "owner", "repo", tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			content, err := fs.ReadFile(fsys, "dir/greeting.txt")
+			if err != nil {
+				t.Fatalf("failed to read cloned file: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, string(content)); diff != "" {
+				t.Errorf("content mismatch (-want +got):\n%s", diff)
+			}
+
+			if commit != tt.expectedCommit {
+				t.Errorf("expected commit %s, got %s", tt.expectedCommit, commit)
+			}
+
+			entries, err := fs.ReadDir(fsys, ".")
+			if err != nil {
+				t.Fatalf("failed to read root: %v", err)
+			}
+
+			if len(entries) != 1 || entries[0].Name() != "dir" || !entries[0].IsDir() {
+				t.Errorf("expected root to contain dir only, got %v", entries)
+			}
+		})
+	}
+}
+
+// TestImportFunc_MemGitClient tests that imports are rendered from an in-memory checkout.
+func TestImportFunc_MemGitClient(t *testing.T) {
+	repoDir := t.TempDir()
+
+	r, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "app/{{.Name}}/main.txt", "Hello, {{.Name}}!")
+	commitFile(t, r, repoDir, "app/README.md", "{{.Name}} v1")
+
+	head, err := r.Head()
+	if err != nil {
+		t.Fatalf("failed to read head: %v", err)
+	}
+
+	if _, err := r.CreateTag("v1", head.Hash(), nil); err != nil {
+		t.Fatalf("failed to tag: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	executor := templit.NewExecutor(templit.NewMemGitClient("main", ""))
+
+	importFunc := executor.ImportFunc(outputDir)
+	if _, err := importFunc("file://"+repoDir+"//app@v1", "out", map[string]string{"Name": "john"}); err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+
+	expected := map[string]string{
+		"out/john/main.txt": "Hello, john!",
+		"out/README.md":     "john v1",
+	}
+
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+
+		if diff := cmp.Diff(want, string(content)); diff != "" {
+			t.Errorf("%s mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package templit
+
+import (
+	"strings"
+)
+
+// hunk replaces the base lines [start, end) with lines.
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs line by line.
+// Changes that overlap or touch and differ are marked with conflict markers labelled oursLabel and theirsLabel.
+// It returns the merged text and whether it contains conflicts.
+func Merge3(base, ours, theirs, oursLabel, theirsLabel string) (string, bool) {
+	if ours == theirs || theirs == base {
+		return ours, false
+	}
+	if ours == base {
+		return theirs, false
+	}
+
+	baseLines := splitLines(base)
+	oursHunks := hunks(diffLines(baseLines, splitLines(ours)))
+	theirsHunks := hunks(diffLines(baseLines, splitLines(theirs)))
+
+	var b strings.Builder
+	var conflict bool
+	pos := 0
+
+	for len(oursHunks) > 0 || len(theirsHunks) > 0 {
+		// start a group with the earliest hunk
+		var oursGroup, theirsGroup []hunk
+		var first hunk
+		if len(theirsHunks) == 0 || (len(oursHunks) > 0 && oursHunks[0].start <= theirsHunks[0].start) {
+			first, oursHunks, oursGroup = oursHunks[0], oursHunks[1:], []hunk{oursHunks[0]}
+		} else {
+			first, theirsHunks, theirsGroup = theirsHunks[0], theirsHunks[1:], []hunk{theirsHunks[0]}
+		}
+		start, end := first.start, first.end
+
+		// add every hunk of either side that overlaps or touches the group
+		for grown := true; grown; {
+			grown = false
+			for len(oursHunks) > 0 && oursHunks[0].start <= end {
+				oursGroup = append(oursGroup, oursHunks[0])
+				end = max(end, oursHunks[0].end)
+				oursHunks, grown = oursHunks[1:], true
+			}
+			for len(theirsHunks) > 0 && theirsHunks[0].start <= end {
+				theirsGroup = append(theirsGroup, theirsHunks[0])
+				end = max(end, theirsHunks[0].end)
+				theirsHunks, grown = theirsHunks[1:], true
+			}
+		}
+
+		writeLines(&b, baseLines[pos:start])
+		pos = end
+
+		oursText := applyHunks(baseLines, start, end, oursGroup)
+		theirsText := applyHunks(baseLines, start, end, theirsGroup)
+
+		switch {
+		case len(theirsGroup) == 0 || oursText == theirsText:
+			b.WriteString(oursText)
+		case len(oursGroup) == 0:
+			b.WriteString(theirsText)
+		default:
+			conflict = true
+			b.WriteString("<<<<<<< " + oursLabel + "\n")
+			b.WriteString(withNewline(oursText))
+			b.WriteString("=======\n")
+			b.WriteString(withNewline(theirsText))
+			b.WriteString(">>>>>>> " + theirsLabel + "\n")
+		}
+	}
+
+	writeLines(&b, baseLines[pos:])
+
+	return b.String(), conflict
+}
+
+// hunks groups the changes of an edit script into hunks of the base.
+func hunks(ops []diffOp) []hunk {
+	var result []hunk
+	var current *hunk
+	pos := 0
+
+	for _, op := range ops {
+		if op.Kind == ' ' {
+			if current != nil {
+				result = append(result, *current)
+				current = nil
+			}
+			pos++
+			continue
+		}
+
+		if current == nil {
+			current = &hunk{start: pos, end: pos}
+		}
+
+		if op.Kind == '-' {
+			pos++
+			current.end = pos
+		} else {
+			current.lines = append(current.lines, op.Line)
+		}
+	}
+
+	if current != nil {
+		result = append(result, *current)
+	}
+
+	return result
+}
+
+// applyHunks returns the base lines [start, end) with the given hunks, which lie within that range, applied.
+func applyHunks(base []string, start, end int, hunks []hunk) string {
+	var b strings.Builder
+	pos := start
+
+	for _, h := range hunks {
+		writeLines(&b, base[pos:h.start])
+		writeLines(&b, h.lines)
+		pos = h.end
+	}
+
+	writeLines(&b, base[pos:end])
+
+	return b.String()
+}
+
+// writeLines writes lines to b.
+func writeLines(b *strings.Builder, lines []string) {
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+}
+
+// withNewline terminates text with a newline unless it is empty or already ends with one.
+func withNewline(text string) string {
+	if text == "" || strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
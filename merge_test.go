@@ -0,0 +1,78 @@
+package templit_test
+
+import (
+	"testing"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestMerge3 tests the Merge3 function.
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name             string
+		base             string
+		ours             string
+		theirs           string
+		expected         string
+		expectedConflict bool
+	}{
+		{
+			name:     "only ours changed",
+			base:     "a\nb\nc\n",
+			ours:     "a\nB\nc\n",
+			theirs:   "a\nb\nc\n",
+			expected: "a\nB\nc\n",
+		},
+		{
+			name:     "only theirs changed",
+			base:     "a\nb\nc\n",
+			ours:     "a\nb\nc\n",
+			theirs:   "a\nb\nC\n",
+			expected: "a\nb\nC\n",
+		},
+		{
+			name:     "separate changes",
+			base:     "1\n2\n3\n4\n5\n",
+			ours:     "1\nours\n3\n4\n5\n",
+			theirs:   "1\n2\n3\n4\ntheirs\n",
+			expected: "1\nours\n3\n4\ntheirs\n",
+		},
+		{
+			name:     "same change on both sides",
+			base:     "1\n2\n3\n",
+			ours:     "1\nsame\n3\n",
+			theirs:   "1\nsame\n3\nadded\n",
+			expected: "1\nsame\n3\nadded\n",
+		},
+		{
+			name:             "overlapping changes",
+			base:             "1\n2\n3\n",
+			ours:             "1\nours\n3\n",
+			theirs:           "1\ntheirs\n3\n",
+			expected:         "1\n<<<<<<< local\nours\n=======\ntheirs\n>>>>>>> template\n3\n",
+			expectedConflict: true,
+		},
+		{
+			name:             "conflict without trailing newline",
+			base:             "1\n2",
+			ours:             "1\nours",
+			theirs:           "1\ntheirs",
+			expected:         "1\n<<<<<<< local\nours\n=======\ntheirs\n>>>>>>> template\n",
+			expectedConflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, conflict := templit.Merge3(tt.base, tt.ours, tt.theirs, "local", "template")
+			if conflict != tt.expectedConflict {
+				t.Errorf("expected conflict %v, got %v", tt.expectedConflict, conflict)
+			}
+
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
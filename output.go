@@ -0,0 +1,511 @@
+package templit
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output receives the directories and files generated by an Executor.
+// Paths are the OS paths below the output directory passed to WalkAndProcessDir or ImportFunc. In-memory and
+// archive outputs store them relative to that directory.
+// Every Output creates the missing parent directories of written files with mode 0755, so a walk produces
+// the same tree whatever the output.
+type Output interface {
+	// MkdirAll creates the directory path along with any missing parents.
+	MkdirAll(path string, perm fs.FileMode) error
+	// WriteFile creates or replaces the file path with data. Missing parent directories are created.
+	WriteFile(path string, data []byte, perm fs.FileMode) error
+	// Remove removes the file or empty directory path.
+	Remove(path string) error
+}
+
+// OutputReader is implemented by outputs that can read back existing files.
+// Conflicts with existing files are only detected for outputs implementing it.
+type OutputReader interface {
+	// ReadFile returns the content of the existing file path.
+	ReadFile(path string) ([]byte, error)
+}
+
+// DiskOutput writes generated files to the real filesystem. It is the default Output of an Executor.
+type DiskOutput struct{}
+
+// MkdirAll creates the directory path along with any missing parents.
+func (DiskOutput) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// WriteFile creates or replaces the file path with data. Missing parent directories are created.
+func (DiskOutput) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, perm)
+}
+
+// Remove removes the file or empty directory path.
+func (DiskOutput) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// ReadFile returns the content of the existing file path.
+func (DiskOutput) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// MemFile is a file or directory held by a MemOutput.
+type MemFile struct {
+	Data []byte
+	Mode fs.FileMode
+}
+
+// MemOutput keeps generated files in memory.
+type MemOutput struct {
+	// Root is the directory the paths of Files are relative to. Paths outside of it are rejected.
+	// An Executor sets an empty Root to the output directory it generates files into.
+	Root string
+	// Files maps the slash separated paths of generated files and directories, relative to Root, to their content.
+	// Directories have fs.ModeDir set in their mode.
+	Files map[string]MemFile
+
+	mu sync.Mutex
+}
+
+// NewMemOutput creates an empty MemOutput.
+func NewMemOutput() *MemOutput {
+	return &MemOutput{Files: map[string]MemFile{}}
+}
+
+// MkdirAll creates the directory path along with any missing parents.
+func (m *MemOutput) MkdirAll(p string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, err := archivePath(m.Root, p)
+	if err != nil {
+		return err
+	}
+
+	return m.mkdirAll(p, name, perm)
+}
+
+// WriteFile creates or replaces the file path with data. Missing parent directories are created.
+func (m *MemOutput) WriteFile(p string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, err := archivePath(m.Root, p)
+	if err != nil {
+		return err
+	}
+
+	if f, ok := m.files()[name]; ok && f.Mode.IsDir() {
+		return &fs.PathError{Op: "write", Path: p, Err: fs.ErrExist}
+	}
+
+	if err := m.mkdirAll(p, path.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	m.Files[name] = MemFile{Data: append([]byte(nil), data...), Mode: perm}
+
+	return nil
+}
+
+// Remove removes the file or empty directory path.
+func (m *MemOutput) Remove(p string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, err := archivePath(m.Root, p)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := m.files()[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: p, Err: fs.ErrNotExist}
+	}
+
+	for other := range m.Files {
+		if strings.HasPrefix(other, name+"/") {
+			return &fs.PathError{Op: "remove", Path: p, Err: fmt.Errorf("directory not empty")}
+		}
+	}
+
+	delete(m.Files, name)
+
+	return nil
+}
+
+// ReadFile returns the content of the existing file path.
+func (m *MemOutput) ReadFile(p string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name, err := archivePath(m.Root, p)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := m.files()[name]
+	if !ok || f.Mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: p, Err: fs.ErrNotExist}
+	}
+
+	return append([]byte(nil), f.Data...), nil
+}
+
+// mkdirAll adds the directory name and its parents that do not exist yet. p is the path used in errors.
+func (m *MemOutput) mkdirAll(p, name string, perm fs.FileMode) error {
+	for _, dir := range parentDirs(name) {
+		if f, ok := m.files()[dir]; ok && !f.Mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: p, Err: fs.ErrExist}
+		} else if !ok {
+			m.Files[dir] = MemFile{Mode: fs.ModeDir | perm}
+		}
+	}
+
+	return nil
+}
+
+// setRoot sets the root of the output if it has none.
+func (m *MemOutput) setRoot(root string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Root == "" {
+		m.Root = root
+	}
+}
+
+// files returns the files of the output, creating the map if needed.
+func (m *MemOutput) files() map[string]MemFile {
+	if m.Files == nil {
+		m.Files = map[string]MemFile{}
+	}
+	return m.Files
+}
+
+// ZipOutput writes generated files to a zip archive.
+// Close must be called to write the end of the archive.
+type ZipOutput struct {
+	// Root is the directory entry names are relative to. Paths outside of it are rejected.
+	// An Executor sets an empty Root to the output directory it generates files into.
+	Root string
+
+	w    *zip.Writer
+	dirs map[string]bool
+	mu   sync.Mutex
+}
+
+// NewZipOutput creates a ZipOutput writing the archive to w.
+func NewZipOutput(w io.Writer) *ZipOutput {
+	return &ZipOutput{w: zip.NewWriter(w), dirs: map[string]bool{}}
+}
+
+// MkdirAll adds entries for the directory path and any parents not added yet.
+func (z *ZipOutput) MkdirAll(p string, perm fs.FileMode) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	name, err := archivePath(z.Root, p)
+	if err != nil {
+		return err
+	}
+
+	return z.mkdirAll(name, perm)
+}
+
+// WriteFile adds the file path with data. Parent directories are added when missing.
+func (z *ZipOutput) WriteFile(p string, data []byte, perm fs.FileMode) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	name, err := archivePath(z.Root, p)
+	if err != nil {
+		return err
+	}
+
+	if err := z.mkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()}
+	header.SetMode(perm)
+
+	w, err := z.w.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to zip: %w", name, err)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to zip: %w", name, err)
+	}
+
+	return nil
+}
+
+// Remove fails because entries cannot be removed from an archive stream.
+func (z *ZipOutput) Remove(p string) error {
+	return fmt.Errorf("cannot remove %s from a zip archive", p)
+}
+
+// setRoot sets the root of the output if it has none.
+func (z *ZipOutput) setRoot(root string) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+
+	if z.Root == "" {
+		z.Root = root
+	}
+}
+
+// Close writes the end of the archive. It does not close the underlying writer.
+func (z *ZipOutput) Close() error {
+	return z.w.Close()
+}
+
+// mkdirAll adds entries for name and its parents that were not added yet.
+func (z *ZipOutput) mkdirAll(name string, perm fs.FileMode) error {
+	for _, dir := range parentDirs(name) {
+		if z.dirs[dir] {
+			continue
+		}
+
+		header := &zip.FileHeader{Name: dir + "/", Modified: time.Now()}
+		header.SetMode(fs.ModeDir | perm)
+
+		if _, err := z.w.CreateHeader(header); err != nil {
+			return fmt.Errorf("failed to add %s to zip: %w", dir, err)
+		}
+
+		z.dirs[dir] = true
+	}
+
+	return nil
+}
+
+// TarOutput writes generated files to a tar archive.
+// Close must be called to write the end of the archive.
+type TarOutput struct {
+	// Root is the directory entry names are relative to. Paths outside of it are rejected.
+	// An Executor sets an empty Root to the output directory it generates files into.
+	Root string
+
+	w    *tar.Writer
+	dirs map[string]bool
+	mu   sync.Mutex
+}
+
+// NewTarOutput creates a TarOutput writing the archive to w.
+func NewTarOutput(w io.Writer) *TarOutput {
+	return &TarOutput{w: tar.NewWriter(w), dirs: map[string]bool{}}
+}
+
+// MkdirAll adds entries for the directory path and any parents not added yet.
+func (t *TarOutput) MkdirAll(p string, perm fs.FileMode) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	name, err := archivePath(t.Root, p)
+	if err != nil {
+		return err
+	}
+
+	return t.mkdirAll(name, perm)
+}
+
+// WriteFile adds the file path with data. Parent directories are added when missing.
+func (t *TarOutput) WriteFile(p string, data []byte, perm fs.FileMode) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	name, err := archivePath(t.Root, p)
+	if err != nil {
+		return err
+	}
+
+	if err := t.mkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     int64(perm.Perm()),
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	}
+
+	if err := t.w.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s to tar: %w", name, err)
+	}
+
+	if _, err := t.w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to tar: %w", name, err)
+	}
+
+	return nil
+}
+
+// Remove fails because entries cannot be removed from an archive stream.
+func (t *TarOutput) Remove(p string) error {
+	return fmt.Errorf("cannot remove %s from a tar archive", p)
+}
+
+// setRoot sets the root of the output if it has none.
+func (t *TarOutput) setRoot(root string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.Root == "" {
+		t.Root = root
+	}
+}
+
+// Close writes the end of the archive. It does not close the underlying writer.
+func (t *TarOutput) Close() error {
+	return t.w.Close()
+}
+
+// mkdirAll adds entries for name and its parents that were not added yet.
+func (t *TarOutput) mkdirAll(name string, perm fs.FileMode) error {
+	for _, dir := range parentDirs(name) {
+		if t.dirs[dir] {
+			continue
+		}
+
+		header := &tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     dir + "/",
+			Mode:     int64(perm.Perm()),
+			ModTime:  time.Now(),
+		}
+
+		if err := t.w.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to add %s to tar: %w", dir, err)
+		}
+
+		t.dirs[dir] = true
+	}
+
+	return nil
+}
+
+// OutputOp is an operation recorded by a RecordOutput.
+type OutputOp struct {
+	// Op is one of "mkdir", "write" or "remove".
+	Op   string
+	Path string
+	Data []byte
+	Mode fs.FileMode
+}
+
+// RecordOutput records the operations of an Executor without performing them, for use in tests.
+type RecordOutput struct {
+	Ops []OutputOp
+
+	mu sync.Mutex
+}
+
+// MkdirAll records the creation of the directory path.
+func (r *RecordOutput) MkdirAll(path string, perm fs.FileMode) error {
+	r.record(OutputOp{Op: "mkdir", Path: path, Mode: perm})
+	return nil
+}
+
+// WriteFile records writing data to the file path.
+func (r *RecordOutput) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	r.record(OutputOp{Op: "write", Path: path, Data: append([]byte(nil), data...), Mode: perm})
+	return nil
+}
+
+// Remove records the removal of path.
+func (r *RecordOutput) Remove(path string) error {
+	r.record(OutputOp{Op: "remove", Path: path})
+	return nil
+}
+
+// Files returns the content of the recorded files that were not removed afterwards, keyed by path.
+func (r *RecordOutput) Files() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	files := map[string]string{}
+	for _, op := range r.Ops {
+		switch op.Op {
+		case "write":
+			files[op.Path] = string(op.Data)
+		case "remove":
+			delete(files, op.Path)
+		}
+	}
+
+	return files
+}
+
+// record appends op to the recorded operations.
+func (r *RecordOutput) record(op OutputOp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Ops = append(r.Ops, op)
+}
+
+// archivePath returns the slash separated path of p relative to root, which is used inside archives and
+// in-memory outputs. An empty root is the working directory. Paths outside of root are rejected instead of
+// being rewritten, so different paths never end up as the same entry.
+func archivePath(root, p string) (string, error) {
+	rel := p
+	if root != "" {
+		var err error
+		if rel, err = filepath.Rel(root, p); err != nil {
+			return "", fmt.Errorf("path %s is outside of the output directory %s: %w", p, root, err)
+		}
+	}
+
+	name := path.Clean(filepath.ToSlash(rel))
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		if root == "" {
+			return "", fmt.Errorf("path %s is outside of the output directory", p)
+		}
+		return "", fmt.Errorf("path %s is outside of the output directory %s", p, root)
+	}
+
+	return name, nil
+}
+
+// rootOutput is implemented by outputs that keep paths relative to a root directory.
+type rootOutput interface {
+	setRoot(root string)
+}
+
+// setOutputRoot tells the output of the executor which directory it generates files into.
+func (e *Executor) setOutputRoot(root string) {
+	if out, ok := e.out.(rootOutput); ok {
+		out.setRoot(root)
+	}
+}
+
+// parentDirs returns name and its parent directories, outermost first, excluding the root.
+func parentDirs(name string) []string {
+	var dirs []string
+	for dir := name; dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+
+	return dirs
+}
@@ -0,0 +1,304 @@
+package templit_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestOutput tests that generated files are written to the configured output.
+func TestOutput(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/{{.Name}}.txt":  {Data: []byte("Hello, {{.Name}}!"), Mode: 0644},
+		"app/docs/README.md": {Data: []byte("# {{.Name}}"), Mode: 0644},
+		"app/-skipped.txt":   {Data: []byte("skipped"), Mode: 0644},
+	}
+
+	outputDir := filepath.Join(t.TempDir(), "out")
+	expected := map[string]string{
+		"john.txt":       "Hello, john!",
+		"docs/README.md": "# john",
+	}
+
+	tests := []struct {
+		name  string
+		setup func() (templit.Output, func() (map[string]string, error))
+	}{
+		{
+			name: "memory",
+			setup: func() (templit.Output, func() (map[string]string, error)) {
+				out := templit.NewMemOutput()
+				return out, func() (map[string]string, error) {
+					return memFiles(out), nil
+				}
+			},
+		},
+		{
+			name: "recorder",
+			setup: func() (templit.Output, func() (map[string]string, error)) {
+				out := &templit.RecordOutput{}
+				return out, func() (map[string]string, error) {
+					files := map[string]string{}
+					for name, content := range out.Files() {
+						rel, err := filepath.Rel(outputDir, name)
+						if err != nil {
+							return nil, err
+						}
+						files[filepath.ToSlash(rel)] = content
+					}
+					return files, nil
+				}
+			},
+		},
+		{
+			name: "zip",
+			setup: func() (templit.Output, func() (map[string]string, error)) {
+				var buf bytes.Buffer
+				out := templit.NewZipOutput(&buf)
+				return out, func() (map[string]string, error) {
+					if err := out.Close(); err != nil {
+						return nil, err
+					}
+
+					r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+					if err != nil {
+						return nil, err
+					}
+
+					files := map[string]string{}
+					for _, f := range r.File {
+						if f.FileInfo().IsDir() {
+							continue
+						}
+
+						rc, err := f.Open()
+						if err != nil {
+							return nil, err
+						}
+						content, err := io.ReadAll(rc)
+						rc.Close()
+						if err != nil {
+							return nil, err
+						}
+						files[f.Name] = string(content)
+					}
+					return files, nil
+				}
+			},
+		},
+		{
+			name: "tar",
+			setup: func() (templit.Output, func() (map[string]string, error)) {
+				var buf bytes.Buffer
+				out := templit.NewTarOutput(&buf)
+				return out, func() (map[string]string, error) {
+					if err := out.Close(); err != nil {
+						return nil, err
+					}
+
+					r := tar.NewReader(&buf)
+					files := map[string]string{}
+					for {
+						header, err := r.Next()
+						if errors.Is(err, io.EOF) {
+							break
+						}
+						if err != nil {
+							return nil, err
+						}
+						if header.Typeflag != tar.TypeReg {
+							continue
+						}
+
+						content, err := io.ReadAll(r)
+						if err != nil {
+							return nil, err
+						}
+						files[header.Name] = string(content)
+					}
+					return files, nil
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, files := tt.setup()
+
+			executor := templit.NewExecutor(nil, templit.WithOutput(out))
+			if err := executor.WalkAndProcessFS(fsys, "app", outputDir, map[string]string{"Name": "john"}); err != nil {
+				t.Fatalf("failed to process fs: %v", err)
+			}
+
+			got, err := files()
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+
+			if diff := cmp.Diff(expected, got); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestOutput_OutsideRoot tests that in-memory and archive outputs reject paths outside of their root.
+func TestOutput_OutsideRoot(t *testing.T) {
+	tests := []struct {
+		name string
+		root string
+		path string
+	}{
+		{name: "outside root", root: "out", path: "other/a.txt"},
+		{name: "parent of root", root: "out", path: "out/../../a.txt"},
+		{name: "relative parent", root: "", path: "../a.txt"},
+		{name: "absolute path", root: "", path: filepath.Join(t.TempDir(), "a.txt")},
+	}
+
+	outputs := map[string]func(root string) templit.Output{
+		"memory": func(root string) templit.Output {
+			out := templit.NewMemOutput()
+			out.Root = root
+			return out
+		},
+		"zip": func(root string) templit.Output {
+			out := templit.NewZipOutput(io.Discard)
+			out.Root = root
+			return out
+		},
+		"tar": func(root string) templit.Output {
+			out := templit.NewTarOutput(io.Discard)
+			out.Root = root
+			return out
+		},
+	}
+
+	for _, tt := range tests {
+		for kind, newOutput := range outputs {
+			t.Run(tt.name+"/"+kind, func(t *testing.T) {
+				out := newOutput(tt.root)
+				if err := out.WriteFile(tt.path, []byte("a"), 0644); err == nil {
+					t.Errorf("expected writing %s outside of %q to fail", tt.path, tt.root)
+				}
+				if err := out.MkdirAll(tt.path, 0755); err == nil {
+					t.Errorf("expected creating %s outside of %q to fail", tt.path, tt.root)
+				}
+			})
+		}
+	}
+}
+
+// TestMemOutput_Remove tests removing files and directories from a MemOutput.
+func TestMemOutput_Remove(t *testing.T) {
+	out := templit.NewMemOutput()
+
+	if err := out.WriteFile("dir/file.txt", []byte("content"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := out.MkdirAll("dir", 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+
+	if err := out.Remove("dir"); err == nil {
+		t.Errorf("expected removing a non-empty directory to fail")
+	}
+
+	if err := out.Remove("dir/file.txt"); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+
+	if err := out.Remove("dir"); err != nil {
+		t.Fatalf("failed to remove dir: %v", err)
+	}
+
+	if len(out.Files) != 0 {
+		t.Errorf("expected no files, got %v", out.Files)
+	}
+}
+
+// TestOutput_WriteFileParents tests that every output creates the missing parent directories of written files.
+func TestOutput_WriteFileParents(t *testing.T) {
+	expected := []string{"a", "a/b"}
+
+	t.Run("disk", func(t *testing.T) {
+		root := t.TempDir()
+		if err := (templit.DiskOutput{}).WriteFile(filepath.Join(root, "a", "b", "c.txt"), []byte("c"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+
+		var dirs []string
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() && p != root {
+				rel, _ := filepath.Rel(root, p)
+				dirs = append(dirs, filepath.ToSlash(rel))
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("failed to walk output: %v", err)
+		}
+
+		if diff := cmp.Diff(expected, dirs); diff != "" {
+			t.Errorf("directories mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		out := templit.NewMemOutput()
+		if err := out.WriteFile("a/b/c.txt", []byte("c"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+
+		var dirs []string
+		for name, f := range out.Files {
+			if f.Mode.IsDir() {
+				dirs = append(dirs, name)
+			}
+		}
+		sort.Strings(dirs)
+
+		if diff := cmp.Diff(expected, dirs); diff != "" {
+			t.Errorf("directories mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		var buf bytes.Buffer
+		out := templit.NewZipOutput(&buf)
+		if err := out.WriteFile("a/b/c.txt", []byte("c"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if err := out.Close(); err != nil {
+			t.Fatalf("failed to close zip: %v", err)
+		}
+
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("failed to read zip: %v", err)
+		}
+
+		var dirs []string
+		for _, f := range r.File {
+			if f.FileInfo().IsDir() {
+				dirs = append(dirs, strings.TrimSuffix(f.Name, "/"))
+			}
+		}
+
+		if diff := cmp.Diff(expected, dirs); diff != "" {
+			t.Errorf("directories mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
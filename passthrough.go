@@ -0,0 +1,52 @@
+package templit
+
+import (
+	"bytes"
+	"strings"
+)
+
+// binarySniffLen is the number of leading bytes searched for a NUL byte to detect binary files, like git does.
+const binarySniffLen = 8000
+
+// WithCopyOnly makes the executor copy the template files matching any of globs verbatim instead of rendering them.
+// Globs are matched against the slash separated path of the file relative to the template directory, like conflict
+// rules, e.g. "*.png" or "charts/**". Binary files are always copied verbatim. Their names are still rendered.
+func WithCopyOnly(globs ...string) ExecutorOption {
+	return func(e *Executor) {
+		e.copyOnly = globs
+	}
+}
+
+// isBinary reports whether content looks like a binary file, which is when its leading bytes contain a NUL byte.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0
+}
+
+// isCopyOnly reports whether the template file at the slash separated path rel, relative to its template directory,
+// is copied verbatim.
+func (e *Executor) isCopyOnly(rel string, content []byte) bool {
+	for _, glob := range e.copyOnly {
+		if matchGlob(glob, rel) {
+			return true
+		}
+	}
+
+	return isBinary(content)
+}
+
+// relPath returns the slash separated path name relative to the directory base of the same fs.FS.
+func relPath(base, name string) string {
+	if name == base {
+		return "."
+	}
+
+	if base == "." || base == "" {
+		return name
+	}
+
+	if rel, ok := strings.CutPrefix(name, base+"/"); ok {
+		return rel
+	}
+
+	return name
+}
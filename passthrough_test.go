@@ -0,0 +1,38 @@
+package templit_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestCopyOnly tests that binary and copy-only files are copied verbatim while their names are rendered.
+func TestCopyOnly(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/{{.Name}}.txt":             {Data: []byte("Hello, {{.Name}}!")},
+		"app/{{.Name}}.png":             {Data: []byte("\x89PNG\x00{{ not a template")},
+		"app/charts/values.yaml":        {Data: []byte("image: {{ .Values.image }}")},
+		"app/charts/{{.Name}}/app.yaml": {Data: []byte("name: {{ .Release.Name }}")},
+		"app/docs/logo.svg":             {Data: []byte("<svg>{{</svg>")},
+	}
+
+	out := templit.NewMemOutput()
+	executor := templit.NewExecutor(nil, templit.WithOutput(out), templit.WithCopyOnly("charts/**", "*.svg"))
+	if err := executor.WalkAndProcessFS(fsys, "app", "out", map[string]string{"Name": "demo"}); err != nil {
+		t.Fatalf("failed to process fs: %v", err)
+	}
+
+	expected := map[string]string{
+		"demo.txt":             "Hello, demo!",
+		"demo.png":             "\x89PNG\x00{{ not a template",
+		"charts/values.yaml":   "image: {{ .Values.image }}",
+		"charts/demo/app.yaml": "name: {{ .Release.Name }}",
+		"docs/logo.svg":        "<svg>{{</svg>",
+	}
+
+	if diff := cmp.Diff(expected, memFiles(out)); diff != "" {
+		t.Errorf("files mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,204 @@
+package templit
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// PlanStatus describes how an output path compares to the existing output directory.
+type PlanStatus string
+
+const (
+	// PlanNew marks a path that does not exist yet.
+	PlanNew PlanStatus = "new"
+	// PlanChanged marks an existing file whose content would change.
+	PlanChanged PlanStatus = "changed"
+	// PlanUnchanged marks an existing path that would stay as it is.
+	PlanUnchanged PlanStatus = "unchanged"
+	// PlanRemoved marks an existing path that would be removed.
+	PlanRemoved PlanStatus = "removed"
+	// PlanConflict marks an existing file whose content differs and that the conflict policy of the file,
+	// other than overwrite, would resolve when writing.
+	PlanConflict PlanStatus = "conflict"
+)
+
+// PlanEntry is an output path of a Plan.
+type PlanEntry struct {
+	Path   string      `json:"path"`
+	Status PlanStatus  `json:"status"`
+	Mode   fs.FileMode `json:"mode"`
+	Dir    bool        `json:"dir,omitempty"`
+	// Source is the template the path was generated from.
+	Source string `json:"source,omitempty"`
+	// Data is the generated content of a file.
+	Data []byte `json:"-"`
+}
+
+// Plan is an Output that records what would be generated without writing anything.
+// Use it with WithOutput for a dry run.
+type Plan struct {
+	// Root is the output directory existing files are compared against. Entry paths are relative to it.
+	Root    string      `json:"root"`
+	Entries []PlanEntry `json:"entries"`
+
+	mu      sync.Mutex
+	sources map[string]string
+}
+
+// NewPlan creates an empty plan for the output directory root.
+func NewPlan(root string) *Plan {
+	return &Plan{Root: root}
+}
+
+// MkdirAll records the directory path.
+func (p *Plan) MkdirAll(path string, perm fs.FileMode) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := PlanNew
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		status = PlanUnchanged
+	}
+
+	p.record(path, PlanEntry{Status: status, Mode: fs.ModeDir | perm, Dir: true})
+
+	return nil
+}
+
+// WriteFile records the file path and compares data with its existing content.
+func (p *Plan) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := PlanNew
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(existing, data):
+		status = PlanUnchanged
+	case err == nil:
+		status = PlanChanged
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	p.record(path, PlanEntry{Status: status, Mode: perm, Data: append([]byte(nil), data...)})
+
+	return nil
+}
+
+// conflict records the file path that conflicts with its existing content.
+func (p *Plan) conflict(path string, data []byte, perm fs.FileMode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.record(path, PlanEntry{Status: PlanConflict, Mode: perm, Data: append([]byte(nil), data...)})
+}
+
+// Remove records the removal of path if it exists.
+func (p *Plan) Remove(path string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	p.record(path, PlanEntry{Status: PlanRemoved, Mode: info.Mode(), Dir: info.IsDir()})
+
+	return nil
+}
+
+// ReadFile returns the content of the file path in the output directory.
+func (p *Plan) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Sorted returns the entries of the plan sorted by path.
+func (p *Plan) Sorted() []PlanEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entries := append([]PlanEntry(nil), p.Entries...)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return entries
+}
+
+// Lookup returns the entry of the output path path.
+func (p *Plan) Lookup(path string) (PlanEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rel := p.rel(path)
+	for _, entry := range p.Entries {
+		if entry.Path == rel {
+			return entry, true
+		}
+	}
+
+	return PlanEntry{}, false
+}
+
+// setSource records the template the output path is generated from.
+func (p *Plan) setSource(path, source string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sources == nil {
+		p.sources = map[string]string{}
+	}
+	p.sources[filepath.Clean(path)] = source
+}
+
+// record adds or replaces the entry of path. The output directory itself is not recorded.
+func (p *Plan) record(path string, entry PlanEntry) {
+	entry.Path = p.rel(path)
+	if entry.Path == "." {
+		return
+	}
+
+	entry.Source = p.sources[filepath.Clean(path)]
+
+	for i, existing := range p.Entries {
+		if existing.Path == entry.Path {
+			p.Entries[i] = entry
+			return
+		}
+	}
+
+	p.Entries = append(p.Entries, entry)
+}
+
+// rel returns path relative to the root of the plan.
+func (p *Plan) rel(path string) string {
+	if p.Root == "" {
+		return filepath.Clean(path)
+	}
+
+	rel, err := filepath.Rel(p.Root, path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+
+	return rel
+}
+
+// sourceOutput is implemented by outputs that record the template each output path is generated from.
+type sourceOutput interface {
+	setSource(path, source string)
+}
+
+// setSource tells the output of the executor which template the output path is generated from.
+func (e *Executor) setSource(path, source string) {
+	if out, ok := e.out.(sourceOutput); ok {
+		out.setSource(path, source)
+	}
+}
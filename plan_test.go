@@ -0,0 +1,103 @@
+package templit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// TestPlan tests that a dry run reports the generation plan without writing files.
+func TestPlan(t *testing.T) {
+	outputDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(outputDir, "greeting.txt"), []byte("Hello, John!\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, "info.txt"), []byte("outdated"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	plan := templit.NewPlan(outputDir)
+	executor := templit.NewExecutor(nil, templit.WithOutput(plan))
+
+	data := map[string]interface{}{
+		"Name":        "John",
+		"Title":       "Project",
+		"Description": "This is a test project.",
+		"Detail":      "more info here.",
+	}
+
+	if err := executor.WalkAndProcessDir("test_data/templates/basic_test", outputDir, data); err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+
+	expected := []templit.PlanEntry{
+		{Path: "docs", Status: templit.PlanNew, Dir: true, Source: "test_data/templates/basic_test/docs"},
+		{Path: "docs/details", Status: templit.PlanNew, Dir: true, Source: "test_data/templates/basic_test/docs/details"},
+		{Path: "docs/details/nested.txt", Status: templit.PlanNew, Source: "test_data/templates/basic_test/docs/details/nested.txt"},
+		{Path: "greeting.txt", Status: templit.PlanUnchanged, Source: "test_data/templates/basic_test/greeting.txt"},
+		{Path: "info.txt", Status: templit.PlanChanged, Source: "test_data/templates/basic_test/info.txt"},
+	}
+
+	if diff := cmp.Diff(expected, plan.Sorted(), cmpopts.IgnoreFields(templit.PlanEntry{}, "Mode", "Data")); diff != "" {
+		t.Errorf("plan mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "docs")); !os.IsNotExist(err) {
+		t.Errorf("expected dry run not to create files, got %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "info.txt"))
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+
+	if string(content) != "outdated" {
+		t.Errorf("expected dry run not to change files, got %q", content)
+	}
+}
+
+// TestPlan_Conflict tests that a dry run reports conflicts instead of resolving them with the conflict policy.
+func TestPlan_Conflict(t *testing.T) {
+	outputDir := t.TempDir()
+
+	for name, content := range map[string]string{"a.txt": "local", "b.txt": "local"} {
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	fsys := fstest.MapFS{
+		"app/a.txt": {Data: []byte("generated"), Mode: 0644},
+		"app/b.txt": {Data: []byte("generated"), Mode: 0644},
+	}
+
+	for _, policy := range []templit.ConflictPolicy{templit.ConflictFail, templit.ConflictPrompt, templit.ConflictSkip} {
+		t.Run(string(policy), func(t *testing.T) {
+			plan := templit.NewPlan(outputDir)
+			executor := templit.NewExecutor(nil,
+				templit.WithOutput(plan),
+				templit.WithConflictPolicy(policy, templit.ConflictRule{Glob: "b.txt", Policy: templit.ConflictOverwrite}),
+			)
+
+			if err := executor.WalkAndProcessFS(fsys, "app", outputDir, nil); err != nil {
+				t.Fatalf("failed to plan: %v", err)
+			}
+
+			expected := []templit.PlanEntry{
+				{Path: "a.txt", Status: templit.PlanConflict, Data: []byte("generated")},
+				{Path: "b.txt", Status: templit.PlanChanged, Data: []byte("generated")},
+			}
+
+			if diff := cmp.Diff(expected, plan.Sorted(), cmpopts.IgnoreFields(templit.PlanEntry{}, "Mode", "Source")); diff != "" {
+				t.Errorf("plan mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
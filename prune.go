@@ -0,0 +1,147 @@
+package templit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// PruneMode decides what happens to stale files, which a previous run generated but the current run no longer generates.
+type PruneMode string
+
+const (
+	// PruneOff ignores stale files. It is the default mode.
+	PruneOff PruneMode = "off"
+	// PruneReport reports stale files without removing them.
+	PruneReport PruneMode = "report"
+	// PruneRemove removes stale files that were not modified since they were generated.
+	PruneRemove PruneMode = "remove"
+)
+
+// StaleFunc is called for every stale file with its slash separated path relative to the output directory
+// and whether it was removed.
+type StaleFunc func(path string, removed bool)
+
+// ParsePruneMode parses the name of a prune mode.
+func ParsePruneMode(name string) (PruneMode, error) {
+	switch mode := PruneMode(name); mode {
+	case PruneOff, PruneReport, PruneRemove:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown prune mode %q", name)
+	}
+}
+
+// WithPrune makes WalkAndProcessDir and WalkAndProcessFS handle stale files with mode after rendering.
+// The previous run is read from the manifest in the output directory. report, if not nil, is called for every stale file.
+func WithPrune(mode PruneMode, report StaleFunc) ExecutorOption {
+	return func(e *Executor) {
+		e.prune = mode
+		e.pruneReport = report
+	}
+}
+
+// Stale returns the sorted paths of the files recorded in m but not in current.
+func (m *Manifest) Stale(current *Manifest) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current.mu.Lock()
+	defer current.mu.Unlock()
+
+	var stale []string
+	for name := range m.Files {
+		if _, ok := current.Files[name]; !ok {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+
+	return stale
+}
+
+// PruneStale handles the stale files of outputDir with the prune mode of the executor and returns their paths.
+// Stale files are the files recorded in the manifest in outputDir but not in the manifest of the executor.
+// Files that no longer exist are ignored and files whose content changed since they were generated are never removed.
+func (e *Executor) PruneStale(outputDir string) ([]string, error) {
+	if e.prune == "" || e.prune == PruneOff {
+		return nil, nil
+	}
+
+	if e.manifest == nil {
+		return nil, errors.New("pruning stale files requires a manifest")
+	}
+
+	reader, ok := e.out.(OutputReader)
+	if !ok {
+		return nil, errors.New("pruning stale files requires an output that can read existing files")
+	}
+
+	previous, err := readManifest(reader, filepath.Join(outputDir, ManifestName))
+	if err != nil || previous == nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, name := range previous.Stale(e.manifest) {
+		filePath := filepath.Join(outputDir, filepath.FromSlash(name))
+
+		content, err := reader.ReadFile(filePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// files changed since they were generated are kept, they may hold work that did not come from the template
+		removed := e.prune == PruneRemove && hashContent(content) == previous.Files[name]
+		if removed {
+			if err := e.out.Remove(filePath); err != nil {
+				return nil, fmt.Errorf("failed to remove stale file %s: %w", name, err)
+			}
+		}
+
+		if e.pruneReport != nil {
+			e.pruneReport(name, removed)
+		}
+		stale = append(stale, name)
+	}
+
+	return stale, nil
+}
+
+// pruneAfter runs render, which generates files into outputDir, and prunes the stale files of outputDir afterwards.
+// A temporary manifest records the generated files if the executor has none.
+func (e *Executor) pruneAfter(outputDir string, render func() error) error {
+	if e.prune == "" || e.prune == PruneOff {
+		return render()
+	}
+
+	if e.manifest == nil {
+		e.manifest = NewManifest("")
+		defer func() { e.manifest = nil }()
+	}
+
+	if err := render(); err != nil {
+		return err
+	}
+
+	_, err := e.PruneStale(outputDir)
+	return err
+}
+
+// readManifest reads the manifest at path from reader. It returns nil if there is no manifest.
+func readManifest(reader OutputReader, path string) (*Manifest, error) {
+	content, err := reader.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return parseManifest(content, path)
+}
@@ -0,0 +1,182 @@
+package templit_test
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestExecutor_PruneStale tests handling files that a previous render generated but the current render does not.
+func TestExecutor_PruneStale(t *testing.T) {
+	v1 := fstest.MapFS{
+		"app/kept.txt":    {Data: []byte("kept {{.Name}}"), Mode: 0644},
+		"app/dropped.txt": {Data: []byte("dropped"), Mode: 0644},
+		"app/edited.txt":  {Data: []byte("edited"), Mode: 0644},
+		"app/deleted.txt": {Data: []byte("deleted"), Mode: 0644},
+	}
+	v2 := fstest.MapFS{
+		"app/kept.txt": {Data: []byte("kept {{.Name}} v2"), Mode: 0644},
+	}
+	data := map[string]string{"Name": "app"}
+
+	tests := []struct {
+		name          string
+		mode          templit.PruneMode
+		expectedStale map[string]bool
+		expectedFiles map[string]string
+	}{
+		{
+			name: "off",
+			mode: templit.PruneOff,
+			expectedFiles: map[string]string{
+				"kept.txt":    "kept app v2",
+				"dropped.txt": "dropped",
+				"edited.txt":  "edited locally",
+				"mine.txt":    "mine",
+			},
+		},
+		{
+			name:          "report",
+			mode:          templit.PruneReport,
+			expectedStale: map[string]bool{"dropped.txt": false, "edited.txt": false},
+			expectedFiles: map[string]string{
+				"kept.txt":    "kept app v2",
+				"dropped.txt": "dropped",
+				"edited.txt":  "edited locally",
+				"mine.txt":    "mine",
+			},
+		},
+		{
+			name:          "remove",
+			mode:          templit.PruneRemove,
+			expectedStale: map[string]bool{"dropped.txt": true, "edited.txt": false},
+			expectedFiles: map[string]string{
+				"kept.txt":   "kept app v2",
+				"edited.txt": "edited locally",
+				"mine.txt":   "mine",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := templit.NewMemOutput()
+
+			manifest := templit.NewManifest("app")
+			executor := templit.NewExecutor(nil, templit.WithOutput(out), templit.WithManifest(manifest))
+			if err := executor.WalkAndProcessFS(v1, "app", "out", data); err != nil {
+				t.Fatalf("failed to render v1: %v", err)
+			}
+
+			content, err := json.Marshal(manifest)
+			if err != nil {
+				t.Fatalf("failed to encode manifest: %v", err)
+			}
+
+			// the manifest of the previous render and local changes
+			for name, content := range map[string]string{
+				"out/" + templit.ManifestName: string(content),
+				"out/edited.txt":              "edited locally",
+				"out/mine.txt":                "mine",
+			} {
+				if err := out.WriteFile(name, []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+			if err := out.Remove("out/deleted.txt"); err != nil {
+				t.Fatalf("failed to remove deleted.txt: %v", err)
+			}
+
+			var stale map[string]bool
+			executor = templit.NewExecutor(nil, templit.WithOutput(out), templit.WithPrune(tt.mode, func(path string, removed bool) {
+				if stale == nil {
+					stale = map[string]bool{}
+				}
+				stale[path] = removed
+			}))
+			if err := executor.WalkAndProcessFS(v2, "app", "out", data); err != nil {
+				t.Fatalf("failed to render v2: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expectedStale, stale); diff != "" {
+				t.Errorf("stale files mismatch (-want +got):\n%s", diff)
+			}
+
+			files := memFiles(out)
+			delete(files, templit.ManifestName)
+
+			if diff := cmp.Diff(tt.expectedFiles, files); diff != "" {
+				t.Errorf("files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestExecutor_PruneStaleConflict tests that files kept by the conflict policy are not stale.
+func TestExecutor_PruneStaleConflict(t *testing.T) {
+	v1 := fstest.MapFS{"app/a.txt": {Data: []byte("v1"), Mode: 0644}}
+	v2 := fstest.MapFS{"app/a.txt": {Data: []byte("v2"), Mode: 0644}}
+
+	tests := []struct {
+		name          string
+		policy        templit.ConflictPolicy
+		expectedFiles map[string]string
+	}{
+		{
+			name:          "skip",
+			policy:        templit.ConflictSkip,
+			expectedFiles: map[string]string{"a.txt": "v1"},
+		},
+		{
+			name:          "new",
+			policy:        templit.ConflictNew,
+			expectedFiles: map[string]string{"a.txt": "v1", "a.txt.new": "v2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := templit.NewMemOutput()
+
+			manifest := templit.NewManifest("app")
+			executor := templit.NewExecutor(nil, templit.WithOutput(out), templit.WithManifest(manifest))
+			if err := executor.WalkAndProcessFS(v1, "app", "out", nil); err != nil {
+				t.Fatalf("failed to render v1: %v", err)
+			}
+
+			content, err := json.Marshal(manifest)
+			if err != nil {
+				t.Fatalf("failed to encode manifest: %v", err)
+			}
+			if err := out.WriteFile("out/"+templit.ManifestName, content, 0644); err != nil {
+				t.Fatalf("failed to write manifest: %v", err)
+			}
+
+			var stale []string
+			executor = templit.NewExecutor(nil,
+				templit.WithOutput(out),
+				templit.WithConflictPolicy(tt.policy),
+				templit.WithPrune(templit.PruneRemove, func(path string, removed bool) {
+					stale = append(stale, path)
+				}),
+			)
+			if err := executor.WalkAndProcessFS(v2, "app", "out", nil); err != nil {
+				t.Fatalf("failed to render v2: %v", err)
+			}
+
+			if len(stale) != 0 {
+				t.Errorf("expected no stale files, got %v", stale)
+			}
+
+			files := memFiles(out)
+			delete(files, templit.ManifestName)
+
+			if diff := cmp.Diff(tt.expectedFiles, files); diff != "" {
+				t.Errorf("files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
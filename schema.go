@@ -0,0 +1,123 @@
+package templit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaFileName is the name of the JSON Schema file at the root of a template tree that its data must conform to.
+// It is not generated.
+const SchemaFileName = "templit.schema.json"
+
+// WithSchema validates the data of the rendered template tree against the JSON Schema file at path before any file
+// is generated, in addition to the SchemaFileName of the tree. Imported template trees are validated against their
+// own schema only.
+func WithSchema(path string) ExecutorOption {
+	return func(e *Executor) {
+		e.schema = path
+	}
+}
+
+// validateData validates the data of the template tree at dir of fsys against its schema file and, unless the tree
+// is imported by another one, against the schema of the executor.
+func (e *Executor) validateData(fsys fs.FS, dir string, data interface{}, imported bool) error {
+	content, err := fs.ReadFile(fsys, path.Join(dir, SchemaFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", SchemaFileName, err)
+	}
+	if err == nil {
+		if err := ValidateSchema(SchemaFileName, content, data); err != nil {
+			return err
+		}
+	}
+
+	if e.schema == "" || imported {
+		return nil
+	}
+
+	content, err = os.ReadFile(e.schema)
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	return ValidateSchema(e.schema, content, data)
+}
+
+// ValidateSchema validates data against the JSON Schema schema, which is named name in errors. Data must be
+// encodable as JSON. Every violation is reported with the dotted path of the offending value, like "db.port".
+func ValidateSchema(name string, schema []byte, data interface{}) error {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(name, bytes.NewReader(schema)); err != nil {
+		return fmt.Errorf("failed to parse schema %s: %w", name, err)
+	}
+
+	compiled, err := compiler.Compile(name)
+	if err != nil {
+		return fmt.Errorf("failed to compile schema %s: %w", name, err)
+	}
+
+	// the validator accepts decoded JSON values only
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("failed to decode data: %w", err)
+	}
+
+	err = compiled.Validate(value)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+
+	var messages []string
+	collectViolations(validationErr, &messages)
+	sort.Strings(messages)
+
+	errs := make([]error, len(messages))
+	for i, message := range messages {
+		errs[i] = errors.New(message)
+	}
+
+	return fmt.Errorf("invalid data: %w", errors.Join(errs...))
+}
+
+// collectViolations appends the messages of the innermost causes of err, which name the actual violations.
+func collectViolations(err *jsonschema.ValidationError, messages *[]string) {
+	if len(err.Causes) == 0 {
+		*messages = append(*messages, fmt.Sprintf("%s: %s", dataPath(err.InstanceLocation), err.Message))
+		return
+	}
+
+	for _, cause := range err.Causes {
+		collectViolations(cause, messages)
+	}
+}
+
+// dataPath converts the JSON pointer location of a value to a dotted path. The data itself is "data".
+func dataPath(location string) string {
+	if location == "" || location == "/" {
+		return "data"
+	}
+
+	elems := strings.Split(strings.TrimPrefix(location, "/"), "/")
+	for i, elem := range elems {
+		elems[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(elem)
+	}
+
+	return strings.Join(elems, ".")
+}
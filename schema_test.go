@@ -0,0 +1,131 @@
+package templit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// serviceSchema is a JSON Schema for the data of a service template.
+const serviceSchema = `{
+  "type": "object",
+  "required": ["name", "db"],
+  "properties": {
+    "name": {"type": "string", "pattern": "^[a-z]+$"},
+    "db": {
+      "type": "object",
+      "required": ["host"],
+      "properties": {
+        "host": {"type": "string"},
+        "port": {"type": "integer", "maximum": 65535}
+      }
+    },
+    "tags": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+// TestValidateSchema tests validating data against a JSON Schema with path qualified errors.
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          interface{}
+		expectedError string
+	}{
+		{
+			name: "valid",
+			data: map[string]interface{}{"name": "api", "db": map[string]interface{}{"host": "localhost", "port": 5432}},
+		},
+		{
+			name:          "missing",
+			data:          map[string]interface{}{"name": "api"},
+			expectedError: "invalid data: data: missing properties: 'db'",
+		},
+		{
+			name: "nested",
+			data: map[string]interface{}{
+				"name": "API",
+				"db":   map[string]interface{}{"port": 70000},
+				"tags": []interface{}{"web", 1},
+			},
+			expectedError: "invalid data: db.port: must be <= 65535 but found 70000\ndb: missing properties: 'host'\nname: does not match pattern '^[a-z]+$'\ntags.1: expected string, but got number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := templit.ValidateSchema("service.json", []byte(serviceSchema), tt.data)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Fatalf("failed to validate data: %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.expectedError {
+				t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+
+	if err := templit.ValidateSchema("broken.json", []byte(`{"type": 1}`), nil); err == nil {
+		t.Errorf("expected an error for an invalid schema")
+	}
+}
+
+// TestWalkAndProcessFS_Schema tests that invalid data fails before any file is generated.
+func TestWalkAndProcessFS_Schema(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/templit.schema.json": {Data: []byte(serviceSchema)},
+		"app/a.txt":               {Data: []byte("{{.name}}")},
+		"app/b.txt":               {Data: []byte("{{.db.host}}")},
+	}
+
+	extraSchema := filepath.Join(t.TempDir(), "extra.json")
+	if err := os.WriteFile(extraSchema, []byte(`{"properties": {"name": {"enum": ["api"]}}}`), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		data          map[string]interface{}
+		expectedFiles map[string]string
+		expectError   bool
+	}{
+		{
+			name:          "valid",
+			data:          map[string]interface{}{"name": "api", "db": map[string]interface{}{"host": "localhost"}},
+			expectedFiles: map[string]string{"a.txt": "api", "b.txt": "localhost"},
+		},
+		{
+			name:          "invalid for the tree schema",
+			data:          map[string]interface{}{"name": "api", "db": map[string]interface{}{}},
+			expectedFiles: map[string]string{},
+			expectError:   true,
+		},
+		{
+			name:          "invalid for the executor schema",
+			data:          map[string]interface{}{"name": "web", "db": map[string]interface{}{"host": "localhost"}},
+			expectedFiles: map[string]string{},
+			expectError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := templit.NewMemOutput()
+			executor := templit.NewExecutor(nil, templit.WithOutput(out), templit.WithSchema(extraSchema))
+			err := executor.WalkAndProcessFS(fsys, "app", "out", tt.data)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.expectError, err)
+			}
+
+			if diff := cmp.Diff(tt.expectedFiles, memFiles(out)); diff != "" {
+				t.Errorf("files mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
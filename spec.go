@@ -0,0 +1,331 @@
+package templit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecFileName is the name of the file at the root of a template tree that declares its variables.
+// It is not generated.
+const SpecFileName = "templit.yaml"
+
+// VariableType is the type of a template variable.
+type VariableType string
+
+const (
+	// VariableString is a string. Untyped variables accept any value.
+	VariableString VariableType = "string"
+	// VariableInt is an integer.
+	VariableInt VariableType = "int"
+	// VariableFloat is a number.
+	VariableFloat VariableType = "float"
+	// VariableBool is a boolean.
+	VariableBool VariableType = "bool"
+	// VariableList is a list of values.
+	VariableList VariableType = "list"
+	// VariableMap is a map of values.
+	VariableMap VariableType = "map"
+)
+
+// Spec declares the variables a template tree expects as top-level data fields.
+type Spec struct {
+	// Description describes the template.
+	Description string     `yaml:"description"`
+	Variables   []Variable `yaml:"variables"`
+}
+
+// Variable declares a top-level data field of a template tree.
+type Variable struct {
+	Name        string       `yaml:"name"`
+	Type        VariableType `yaml:"type"`
+	Description string       `yaml:"description"`
+	// Default is used if the data has no value. String defaults are rendered as templates with the data,
+	// including the variables declared before.
+	Default interface{} `yaml:"default"`
+	// Choices are the allowed values.
+	Choices  []interface{} `yaml:"choices"`
+	Required bool          `yaml:"required"`
+	// Pattern is a regular expression string values must match.
+	Pattern string `yaml:"pattern"`
+	// When hides the variable unless it renders to "true" with the data, e.g. "{{.UseDatabase}}".
+	// Hidden variables get no default and are not validated.
+	When string `yaml:"when"`
+}
+
+// LoadSpec reads the SpecFileName file in the directory dir of fsys. It returns nil if there is none.
+func LoadSpec(fsys fs.FS, dir string) (*Spec, error) {
+	content, err := fs.ReadFile(fsys, path.Join(dir, SpecFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SpecFileName, err)
+	}
+
+	spec := &Spec{}
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", SpecFileName, err)
+	}
+
+	for i, v := range spec.Variables {
+		if v.Name == "" {
+			return nil, fmt.Errorf("%s: variable %d has no name", SpecFileName, i+1)
+		}
+
+		switch v.Type {
+		case "", VariableString, VariableInt, VariableFloat, VariableBool, VariableList, VariableMap:
+		default:
+			return nil, fmt.Errorf("%s: variable %q has unknown type %q", SpecFileName, v.Name, v.Type)
+		}
+
+		if v.Pattern != "" {
+			if _, err := regexp.Compile(v.Pattern); err != nil {
+				return nil, fmt.Errorf("%s: variable %q has an invalid pattern: %w", SpecFileName, v.Name, err)
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// Apply returns a copy of data with the defaults of the visible variables filled in and values converted to
+// the declared types. It fails if a visible variable is missing although required, or has a value of the wrong
+// type, that is not one of its choices or that does not match its pattern.
+func (s *Spec) Apply(data map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+
+	var errs []error
+	for _, v := range s.Variables {
+		visible, err := v.Visible(result)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !visible {
+			continue
+		}
+
+		value, ok := result[v.Name]
+		if !ok && v.Default != nil {
+			if value, err = v.DefaultValue(result); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			ok = true
+		}
+
+		if !ok {
+			if v.Required {
+				errs = append(errs, fmt.Errorf("variable %q is required", v.Name))
+			}
+			continue
+		}
+
+		if value, err = v.Validate(value); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		result[v.Name] = value
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid data: %w", err)
+	}
+
+	return result, nil
+}
+
+// applyTo applies the spec to data, which must be a map with string keys or nil.
+func (s *Spec) applyTo(data interface{}) (interface{}, error) {
+	switch data := data.(type) {
+	case nil:
+		return s.Apply(nil)
+	case map[string]interface{}:
+		return s.Apply(data)
+	case map[string]string:
+		values := make(map[string]interface{}, len(data))
+		for k, v := range data {
+			values[k] = v
+		}
+		return s.Apply(values)
+	default:
+		return nil, fmt.Errorf("%s requires map data, got %T", SpecFileName, data)
+	}
+}
+
+// Visible reports whether the When condition of the variable holds for data.
+func (v Variable) Visible(data map[string]interface{}) (bool, error) {
+	if v.When == "" {
+		return true, nil
+	}
+
+	when, err := renderSpecValue(v.When, data)
+	if err != nil {
+		return false, fmt.Errorf("variable %q: when: %w", v.Name, err)
+	}
+
+	visible, err := strconv.ParseBool(strings.TrimSpace(when))
+	if err != nil {
+		return false, fmt.Errorf("variable %q: when must render to a boolean, got %q", v.Name, when)
+	}
+
+	return visible, nil
+}
+
+// Validate converts value to the type of the variable and checks it against its choices and pattern.
+// Strings are parsed as the declared type, so values from the command line or a prompt can be validated.
+func (v Variable) Validate(value interface{}) (interface{}, error) {
+	value, err := convertValue(v.Type, value)
+	if err != nil {
+		return nil, fmt.Errorf("variable %q: %w", v.Name, err)
+	}
+
+	if len(v.Choices) > 0 {
+		var found bool
+		for _, choice := range v.Choices {
+			if choice, err := convertValue(v.Type, choice); err == nil && reflect.DeepEqual(choice, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("variable %q: %v is not one of %v", v.Name, value, v.Choices)
+		}
+	}
+
+	if v.Pattern != "" {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("variable %q: pattern requires a string, got %T", v.Name, value)
+		}
+
+		if ok, _ := regexp.MatchString(v.Pattern, s); !ok {
+			return nil, fmt.Errorf("variable %q: %q does not match %s", v.Name, s, v.Pattern)
+		}
+	}
+
+	return value, nil
+}
+
+// DefaultValue returns the default of the variable, rendering string defaults with data.
+func (v Variable) DefaultValue(data map[string]interface{}) (interface{}, error) {
+	s, ok := v.Default.(string)
+	if !ok {
+		return v.Default, nil
+	}
+
+	value, err := renderSpecValue(s, data)
+	if err != nil {
+		return nil, fmt.Errorf("variable %q: default: %w", v.Name, err)
+	}
+
+	return value, nil
+}
+
+// renderSpecValue renders a template in a spec with data and the default functions.
+func renderSpecValue(text string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.New("spec").Funcs(DefaultFuncMap).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// convertValue converts value to typ, parsing strings and widening numbers. Untyped values are returned as is.
+func convertValue(typ VariableType, value interface{}) (interface{}, error) {
+	switch typ {
+	case "":
+		return value, nil
+	case VariableString:
+		switch value := value.(type) {
+		case string:
+			return value, nil
+		case int, int64, float64, bool:
+			return fmt.Sprint(value), nil
+		}
+	case VariableInt:
+		switch value := value.(type) {
+		case int:
+			return value, nil
+		case int64:
+			return int(value), nil
+		case float64:
+			if value == float64(int(value)) {
+				return int(value), nil
+			}
+		case string:
+			if i, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				return i, nil
+			}
+		}
+	case VariableFloat:
+		switch value := value.(type) {
+		case float64:
+			return value, nil
+		case int:
+			return float64(value), nil
+		case int64:
+			return float64(value), nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				return f, nil
+			}
+		}
+	case VariableBool:
+		switch value := value.(type) {
+		case bool:
+			return value, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+				return b, nil
+			}
+		}
+	case VariableList:
+		switch value := value.(type) {
+		case []interface{}:
+			return value, nil
+		case []string:
+			list := make([]interface{}, len(value))
+			for i, s := range value {
+				list[i] = s
+			}
+			return list, nil
+		case string:
+			// comma separated values
+			var list []interface{}
+			for _, s := range strings.Split(value, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					list = append(list, s)
+				}
+			}
+			return list, nil
+		}
+	case VariableMap:
+		if value, ok := value.(map[string]interface{}); ok {
+			return value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%v is not of type %s", value, typ)
+}
@@ -0,0 +1,118 @@
+package templit_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// specFS is a template tree declaring its variables in a templit.yaml.
+var specFS = fstest.MapFS{
+	"app/templit.yaml": {Data: []byte(`description: service template
+variables:
+  - name: Name
+    description: name of the service
+    required: true
+    pattern: ^[a-z][a-z0-9-]*$
+  - name: Module
+    default: "github.com/acme/{{.Name}}"
+  - name: Port
+    type: int
+    default: 8080
+  - name: Database
+    choices: [none, postgres, mysql]
+    default: none
+  - name: DatabaseURL
+    required: true
+    when: '{{ne .Database "none"}}'
+  - name: Replicas
+    type: int
+`)},
+	"app/main.txt": {Data: []byte("{{.Module}}:{{.Port}} {{.Database}}")},
+}
+
+// TestSpec_Apply tests filling in defaults and validating data declared by a templit.yaml.
+func TestSpec_Apply(t *testing.T) {
+	spec, err := templit.LoadSpec(specFS, "app")
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		data          map[string]interface{}
+		expected      map[string]interface{}
+		expectedError string
+	}{
+		{
+			name: "defaults",
+			data: map[string]interface{}{"Name": "api"},
+			expected: map[string]interface{}{
+				"Name":     "api",
+				"Module":   "github.com/acme/api",
+				"Port":     8080,
+				"Database": "none",
+			},
+		},
+		{
+			name: "conversion and visible variable",
+			data: map[string]interface{}{"Name": "api", "Port": "9090", "Replicas": float64(3), "Database": "postgres", "DatabaseURL": "postgres://db"},
+			expected: map[string]interface{}{
+				"Name":        "api",
+				"Module":      "github.com/acme/api",
+				"Port":        9090,
+				"Replicas":    3,
+				"Database":    "postgres",
+				"DatabaseURL": "postgres://db",
+			},
+		},
+		{
+			name:          "invalid",
+			data:          map[string]interface{}{"Name": "API", "Port": "http", "Database": "sqlite"},
+			expectedError: "invalid data: variable \"Name\": \"API\" does not match ^[a-z][a-z0-9-]*$\nvariable \"Port\": http is not of type int\nvariable \"Database\": sqlite is not one of [none postgres mysql]\nvariable \"DatabaseURL\" is required",
+		},
+		{
+			name:          "required",
+			data:          map[string]interface{}{"Database": "mysql"},
+			expectedError: "invalid data: variable \"Name\" is required\nvariable \"DatabaseURL\" is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := spec.Apply(tt.data)
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to apply spec: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Errorf("data mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestWalkAndProcessFS_Spec tests that walking a template tree applies its templit.yaml without generating it.
+func TestWalkAndProcessFS_Spec(t *testing.T) {
+	out := templit.NewMemOutput()
+	executor := templit.NewExecutor(nil, templit.WithOutput(out))
+	if err := executor.WalkAndProcessFS(specFS, "app", "out", map[string]interface{}{"Name": "api"}); err != nil {
+		t.Fatalf("failed to process fs: %v", err)
+	}
+
+	if diff := cmp.Diff(map[string]string{"main.txt": "github.com/acme/api:8080 none"}, memFiles(out)); diff != "" {
+		t.Errorf("files mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := executor.WalkAndProcessFS(specFS, "app", "out", map[string]interface{}{}); err == nil {
+		t.Errorf("expected an error for missing required variables")
+	}
+}
@@ -0,0 +1,37 @@
+package templit
+
+import "sort"
+
+// WithStrict makes templates fail on keys missing from map data instead of rendering "<no value>". It applies to
+// file contents, front matter, file and directory names, embeds and imports.
+func WithStrict() ExecutorOption {
+	return func(e *Executor) {
+		e.Option("missingkey=error")
+	}
+}
+
+// UnusedVariables returns the sorted top-level keys of data that none of the templates parsed by the executor so far
+// references, including file and directory names, front matter and the defaults and conditions of spec files.
+// Fields only read through functions like index are not detected and are reported as unused.
+func (e *Executor) UnusedVariables(data map[string]interface{}) []string {
+	var unused []string
+	for name := range data {
+		if _, ok := e.used.types[name]; !ok {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+
+	return unused
+}
+
+// recordUsage collects the fields referenced by the template text, which was already parsed successfully.
+// Templates rendered with a range element of an entry name as data do not reference top-level fields.
+func (e *Executor) recordUsage(name, text, left, right string) {
+	if e.elementDepth > 0 {
+		return
+	}
+
+	// parse errors are reported by rendering the text
+	_, _ = e.used.scan(name, text, left, right)
+}
@@ -0,0 +1,93 @@
+package templit_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWithStrict tests that strict executors fail on keys missing from the data.
+func TestWithStrict(t *testing.T) {
+	tests := []struct {
+		name          string
+		fsys          fstest.MapFS
+		expectedError string
+	}{
+		{
+			name:          "content",
+			fsys:          fstest.MapFS{"app/main.txt": {Data: []byte("{{.Nmae}}")}},
+			expectedError: `map has no entry for key "Nmae"`,
+		},
+		{
+			name:          "file name",
+			fsys:          fstest.MapFS{"app/{{.Nmae}}.txt": {Data: []byte("{{.Name}}")}},
+			expectedError: `map has no entry for key "Nmae"`,
+		},
+		{
+			name:          "front matter",
+			fsys:          fstest.MapFS{"app/main.txt": {Data: []byte("---\npath: \"{{.Dir}}/main.txt\"\n---\n{{.Name}}")}},
+			expectedError: `map has no entry for key "Dir"`,
+		},
+		{
+			name: "complete data",
+			fsys: fstest.MapFS{"app/{{.Name}}.txt": {Data: []byte("{{.Name}}")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]interface{}{"Name": "api"}
+
+			executor := templit.NewExecutor(nil, templit.WithOutput(templit.NewMemOutput()), templit.WithStrict())
+			err := executor.WalkAndProcessFS(tt.fsys, "app", "out", data)
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Fatalf("failed to process fs: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+
+			// without strict mode missing keys render as <no value>
+			executor = templit.NewExecutor(nil, templit.WithOutput(templit.NewMemOutput()))
+			if err := executor.WalkAndProcessFS(tt.fsys, "app", "out", data); err != nil {
+				t.Errorf("failed to process fs without strict mode: %v", err)
+			}
+		})
+	}
+}
+
+// TestExecutor_UnusedVariables tests reporting data variables that no template references.
+func TestExecutor_UnusedVariables(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/templit.yaml":                        {Data: []byte("variables:\n  - name: Module\n    default: \"github.com/{{.Owner}}/app\"\n")},
+		"app/{{.Dir}}/main.txt":                   {Data: []byte("---\nskip: \"{{not .WithMain}}\"\n---\n{{.Module}} {{range .Items}}{{.Name}}{{end}}")},
+		"app/{{range .Services}}{{.}}{{end}}.txt": {Data: []byte("{{.}}")},
+		// the fields of range elements are not top-level fields
+		"app/{{range .Apps}}{{.Name}}{{end}}/{{.Port}}.txt": {Data: []byte("{{.Port}}")},
+	}
+
+	data := map[string]interface{}{
+		"Dir":      "cmd",
+		"WithMain": true,
+		"Owner":    "acme",
+		"Items":    []interface{}{map[string]interface{}{"Name": "a"}},
+		"Services": []interface{}{"web"},
+		"Apps":     []interface{}{map[string]interface{}{"Name": "api", "Port": 8080}},
+		"Port":     80,
+		"Name":     "unused",
+		"Extra":    1,
+	}
+
+	executor := templit.NewExecutor(nil, templit.WithOutput(templit.NewMemOutput()))
+	if err := executor.WalkAndProcessFS(fsys, "app", "out", data); err != nil {
+		t.Fatalf("failed to process fs: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"Extra", "Name", "Port"}, executor.UnusedVariables(data)); diff != "" {
+		t.Errorf("unused variables mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -2,6 +2,7 @@ package templit
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,15 +12,83 @@ import (
 // Executor is a wrapper around the template.Template type
 type Executor struct {
 	*template.Template
-	git GitClient
+	git        GitClient
+	cache      *Cache
+	lock       *Lockfile
+	lockUpdate bool
+	out        Output
+	manifest   *Manifest
+
+	conflict       ConflictPolicy
+	conflictRules  []ConflictRule
+	conflictPrompt ConflictPromptFunc
+
+	prune       PruneMode
+	pruneReport StaleFunc
+
+	copyOnly []string
+
+	leftDelim, rightDelim string
+
+	schema string
+	// walkDepth counts nested walks, trees walked at a depth greater than 1 are imported by another one
+	walkDepth int
+
+	// used collects the top-level data fields referenced by the templates the executor parsed
+	used *fieldScanner
+	// elementDepth counts the entries being generated with a range element of their name as data
+	elementDepth int
+}
+
+// ExecutorOption configures an Executor.
+type ExecutorOption func(*Executor)
+
+// WithCache makes the executor resolve embed and import dependencies through the given cache.
+func WithCache(cache *Cache) ExecutorOption {
+	return func(e *Executor) {
+		e.cache = cache
+	}
+}
+
+// WithLockfile makes the executor record every resolved dependency in lock.
+// Dependencies already present in lock are checked out at their pinned commit unless update is true.
+func WithLockfile(lock *Lockfile, update bool) ExecutorOption {
+	return func(e *Executor) {
+		e.lock = lock
+		e.lockUpdate = update
+	}
+}
+
+// WithOutput makes the executor write generated files to out instead of the real filesystem.
+func WithOutput(out Output) ExecutorOption {
+	return func(e *Executor) {
+		e.out = out
+	}
+}
+
+// WithDelims sets the action delimiters of templates and of rendered file and directory names, such as "[[" and "]]".
+// Empty delimiters select the defaults "{{" and "}}". The front matter of a file may set its own delimiters.
+func WithDelims(left, right string) ExecutorOption {
+	return func(e *Executor) {
+		e.leftDelim, e.rightDelim = left, right
+		e.Template.Delims(left, right)
+	}
 }
 
 // New returns a new Executor
-func NewExecutor(gitClient GitClient) *Executor {
-	return &Executor{
+func NewExecutor(gitClient GitClient, opts ...ExecutorOption) *Executor {
+	e := &Executor{
 		Template: template.New("main").Funcs(DefaultFuncMap),
 		git:      gitClient,
+		out:      DiskOutput{},
+		used:     &fieldScanner{types: map[string]VariableType{}},
 	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
 }
 
 // ParsePath parses the given path
@@ -35,39 +104,79 @@ func (e *Executor) ParsePath(inputPath string) error {
 		if err != nil {
 			return fmt.Errorf("failed to read file: %w", err)
 		}
-		if _, err := e.New(inputPath).Parse(string(content)); err != nil {
+		if _, _, _, err := e.parseTemplate(inputPath, content); err != nil {
 			return fmt.Errorf("failed to parse template: %w", err)
 		}
 		return nil
 	}
 
-	err = filepath.Walk(inputPath, func(path string, info os.FileInfo, err error) error {
+	if err := e.parseFS(os.DirFS(inputPath), ".", inputPath); err != nil {
+		return fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	return nil
+}
+
+// ParseFS parses the file or all files below the directory root of fsys, such as an embed.FS.
+// Templates are named by their slash separated path in fsys.
+func (e *Executor) ParseFS(fsys fs.FS, root string) error {
+	if err := e.parseFS(fsys, root, ""); err != nil {
+		return fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	return nil
+}
+
+// parseFS parses the file or all files below the directory root of fsys. Templates are named by their path joined to prefix.
+// Paths excluded by ignore files are skipped.
+func (e *Executor) parseFS(fsys fs.FS, root, prefix string) error {
+	var ignore ignorer
+	return fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return fmt.Errorf("failed to walk directory: %w", err)
 		}
 
-		if info.IsDir() {
+		rel := relPath(root, path)
+		if rel != "." && ignore.ignored(rel, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
 			return nil
 		}
 
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return ignore.load(fsys, path, rel)
+		}
+
 		// Read, parse, and execute template only if it's a file
-		content, err := os.ReadFile(path)
+		content, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return fmt.Errorf("failed to read file: %w", err)
 		}
 
-		if _, err := e.New(path).Parse(string(content)); err != nil {
+		// files copied verbatim are not templates
+		if e.isCopyOnly(rel, content) {
+			return nil
+		}
+
+		if _, _, _, err := e.parseTemplate(templateName(prefix, path), content); err != nil {
 			return fmt.Errorf("failed to parse template: %w", err)
 		}
 
 		return nil
 	})
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to parse templates: %w", err)
+// templateName returns the name of the template at the slash separated path name of an fs.FS.
+// Templates read from the disk are named by their OS path below prefix.
+func templateName(prefix, name string) string {
+	if prefix == "" {
+		return name
 	}
-
-	return nil
+	return filepath.Join(prefix, filepath.FromSlash(name))
 }
 
 // Render executes the template with the given data
@@ -2,6 +2,7 @@ package templit_test
 
 import (
 	"testing"
+	"testing/fstest"
 	"text/template"
 
 	"github.com/euforic/templit"
@@ -88,3 +89,96 @@ func TestRender(t *testing.T) {
 		})
 	}
 }
+
+// TestParseFS tests the ParseFS function.
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/greeting.txt":     {Data: []byte("Hello, {{.Name}}!")},
+		"templates/blocks/block.txt": {Data: []byte(`{{define "block"}}Hey, {{.Name}}{{end}}`)},
+		"other.txt":                  {Data: []byte("other")},
+		// ignored files are not parsed
+		"templates/.templitignore":      {Data: []byte("fixtures/\n")},
+		"templates/fixtures/broken.txt": {Data: []byte("{{.Name")},
+	}
+
+	tests := []struct {
+		name         string
+		root         string
+		templateName string
+		expected     string
+		err          bool
+	}{
+		{
+			name:         "directory",
+			root:         "templates",
+			templateName: "templates/greeting.txt",
+			expected:     "Hello, John!",
+		},
+		{
+			name:         "block in subdirectory",
+			root:         "templates",
+			templateName: "block",
+			expected:     "Hey, John",
+		},
+		{
+			name:         "single file",
+			root:         "other.txt",
+			templateName: "other.txt",
+			expected:     "other",
+		},
+		{
+			name: "missing root",
+			root: "missing",
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := templit.NewExecutor(nil)
+			err := executor.ParseFS(fsys, tt.root)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected error: %v, got: %v", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			result, err := executor.Render(tt.templateName, map[string]string{"Name": "John"})
+			if err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestWithDelims tests rendering templates and names with custom delimiters.
+func TestWithDelims(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/[[.Name]].tmpl":                         {Data: []byte("name: [[.Name]] {{ .Values.name }}")},
+		"app/[[range .Services]][[.]][[end]]/go.mod": {Data: []byte("module [[.]]")},
+		"app/{{.Name}}.txt":                          {Data: []byte("---\ndelims: [\"<%\", \"%>\"]\n---\n<%.Name%> [[.Name]]")},
+	}
+
+	out := templit.NewMemOutput()
+	executor := templit.NewExecutor(nil, templit.WithOutput(out), templit.WithDelims("[[", "]]"))
+	data := map[string]interface{}{"Name": "demo", "Services": []string{"api", "web"}}
+	if err := executor.WalkAndProcessFS(fsys, "app", "out", data); err != nil {
+		t.Fatalf("failed to process fs: %v", err)
+	}
+
+	expected := map[string]string{
+		"demo.tmpl":     "name: demo {{ .Values.name }}",
+		"api/go.mod":    "module api",
+		"web/go.mod":    "module web",
+		"{{.Name}}.txt": "demo [[.Name]]",
+	}
+
+	if diff := cmp.Diff(expected, memFiles(out)); diff != "" {
+		t.Errorf("files mismatch (-want +got):\n%s", diff)
+	}
+}
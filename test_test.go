@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/euforic/templit"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -18,7 +19,7 @@ func (m *MockGitClient) DefaultBranch() string {
 }
 
 // Clone clones a repository to the given destination.
-func (m *MockGitClient) Clone(host, owner, repo, dest string) error {
+func (m *MockGitClient) Clone(host, owner, repo, ref, dest string) error {
 	src := filepath.Join(host, owner, repo)
 	return copyDir(src, dest)
 }
@@ -29,6 +30,26 @@ func (m *MockGitClient) Checkout(path, branch string) error {
 	return nil
 }
 
+// memFileEntries returns the files of out without its directories.
+func memFileEntries(out *templit.MemOutput) map[string]templit.MemFile {
+	files := map[string]templit.MemFile{}
+	for name, f := range out.Files {
+		if !f.Mode.IsDir() {
+			files[name] = f
+		}
+	}
+	return files
+}
+
+// memFiles returns the content of the files of out without its directories.
+func memFiles(out *templit.MemOutput) map[string]string {
+	files := map[string]string{}
+	for name, f := range memFileEntries(out) {
+		files[name] = string(f.Data)
+	}
+	return files
+}
+
 // copyDir copies a directory recursively
 func copyDir(src string, dst string) error {
 	entries, err := os.ReadDir(src)
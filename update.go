@@ -0,0 +1,197 @@
+package templit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+)
+
+// UpdateResult lists the files changed by Update, relative to the output directory.
+type UpdateResult struct {
+	// Added are files the new template version generates for the first time.
+	Added []string
+	// Updated are files that took the template changes, merged cleanly with local changes where needed.
+	Updated []string
+	// Conflicts are files whose local and template changes overlap. They contain conflict markers.
+	Conflicts []string
+	// Removed are files the new template version no longer generates and that were not changed locally.
+	Removed []string
+	// Skipped are files the template changed but that were deleted locally.
+	Skipped []string
+}
+
+// Update merges the changes between two versions of a template into a project generated from the old version.
+// source is the DepInfo string of the template, including the ref the project was generated from.
+// Both versions are rendered, the old one with oldData and the one at ref with newData, and every change
+// between them is merged into outputDir with a three-way merge, keeping local modifications.
+func (e *Executor) Update(source, ref, outputDir string, oldData, newData interface{}) (*UpdateResult, error) {
+	reader, ok := e.out.(OutputReader)
+	if !ok {
+		return nil, errors.New("update requires an output that can read existing files")
+	}
+
+	e.setOutputRoot(outputDir)
+
+	oldDep, err := ParseDepURL(source)
+	if err != nil {
+		return nil, err
+	}
+
+	newDep := *oldDep
+	newDep.Tag = ref
+
+	co, err := e.checkoutDep(oldDep)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := e.renderDep(co, oldDep, outputDir, oldData)
+	if err != nil {
+		co.cleanup()
+		return nil, fmt.Errorf("failed to render %s: %w", oldDep, err)
+	}
+
+	// the manifest describes the new version, including only the files it generates
+	if e.manifest != nil {
+		e.manifest.Source, e.manifest.Commit = newDep.String(), ""
+	}
+
+	// both versions are checked out from the same clone
+	co, err = e.checkoutDepFrom(co, &newDep)
+	if err != nil {
+		return nil, err
+	}
+	defer co.cleanup()
+
+	theirs, err := e.renderDep(co, &newDep, outputDir, newData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", &newDep, err)
+	}
+
+	if e.manifest != nil {
+		e.manifest.Reset()
+	}
+
+	names := make([]string, 0, len(base)+len(theirs))
+	for name, f := range theirs {
+		names = append(names, name)
+		e.recordFile(outputDir, filepath.Join(outputDir, name), f.Data)
+	}
+	for name := range base {
+		if _, ok := theirs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := &UpdateResult{}
+	for _, name := range names {
+		filePath := filepath.Join(outputDir, name)
+		baseFile, inBase := base[name]
+		theirsFile, inTheirs := theirs[name]
+
+		ours, err := reader.ReadFile(filePath)
+		exists := err == nil
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		switch {
+		case !inTheirs:
+			// the template no longer generates the file, remove it unless it was changed locally
+			if exists && bytes.Equal(ours, baseFile.Data) {
+				if err := e.out.Remove(filePath); err != nil {
+					return nil, err
+				}
+				result.Removed = append(result.Removed, name)
+			}
+			continue
+		case !exists && inBase:
+			if !bytes.Equal(baseFile.Data, theirsFile.Data) {
+				result.Skipped = append(result.Skipped, name)
+			}
+			continue
+		case !exists:
+			result.Added = append(result.Added, name)
+			if err := e.writeMerged(filePath, theirsFile.Data, theirsFile.Mode); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		merged, conflict := Merge3(string(baseFile.Data), string(ours), string(theirsFile.Data), "local", "template "+ref)
+		if merged == string(ours) {
+			continue
+		}
+
+		if conflict {
+			result.Conflicts = append(result.Conflicts, name)
+		} else {
+			result.Updated = append(result.Updated, name)
+		}
+
+		if err := e.writeMerged(filePath, []byte(merged), theirsFile.Mode); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// renderDep renders the template dep checked out at co for outputDir into memory and returns the generated files by their path relative to outputDir.
+func (e *Executor) renderDep(co *checkout, dep *DepInfo, outputDir string, data interface{}) (map[string]MemFile, error) {
+	capture := &captureOutput{files: map[string]MemFile{}}
+
+	out := e.out
+	e.out = capture
+	defer func() { e.out = out }()
+
+	if err := e.importCheckout(co, dep, outputDir, ".", data); err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]MemFile, len(capture.files))
+	for name, f := range capture.files {
+		rel, err := filepath.Rel(outputDir, name)
+		if err != nil {
+			return nil, err
+		}
+		files[rel] = f
+	}
+
+	return files, nil
+}
+
+// writeMerged writes the result of merging a file to the output, creating its directory if needed.
+func (e *Executor) writeMerged(filePath string, data []byte, perm fs.FileMode) error {
+	if err := e.out.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	return e.out.WriteFile(filePath, data, perm)
+}
+
+// captureOutput keeps the files of a render in memory by their OS path.
+type captureOutput struct {
+	files map[string]MemFile
+}
+
+// MkdirAll does nothing because only files are captured.
+func (c *captureOutput) MkdirAll(path string, perm fs.FileMode) error {
+	return nil
+}
+
+// WriteFile captures the file path.
+func (c *captureOutput) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	c.files[filepath.Clean(path)] = MemFile{Data: data, Mode: perm}
+	return nil
+}
+
+// Remove removes the captured file path.
+func (c *captureOutput) Remove(path string) error {
+	delete(c.files, filepath.Clean(path))
+	return nil
+}
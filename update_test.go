@@ -0,0 +1,163 @@
+package templit_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/euforic/templit"
+	git "github.com/go-git/go-git/v5"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestExecutor_Update tests merging a new template version into a generated project.
+func TestExecutor_Update(t *testing.T) {
+	repoDir := t.TempDir()
+
+	r, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+
+	tag := func(name string) {
+		head, err := r.Head()
+		if err != nil {
+			t.Fatalf("failed to read head: %v", err)
+		}
+
+		if _, err := r.CreateTag(name, head.Hash(), nil); err != nil {
+			t.Fatalf("failed to tag: %v", err)
+		}
+	}
+
+	commitFile(t, r, repoDir, "tmpl/config.txt", "header\nname={{.Name}}\n1\n2\n3\n4\nfooter\n")
+	commitFile(t, r, repoDir, "tmpl/local.txt", "keep\n")
+	commitFile(t, r, repoDir, "tmpl/dropped.txt", "dropped\n")
+	commitFile(t, r, repoDir, "tmpl/edited.txt", "edited\n")
+	tag("v1")
+
+	commitFile(t, r, repoDir, "tmpl/config.txt", "header v2\nname={{.Name}}\n1\n2\n3\n4\nfooter v2\n")
+	commitFile(t, r, repoDir, "tmpl/local.txt", "keep v2\n")
+	commitFile(t, r, repoDir, "tmpl/added.txt", "added {{.Name}}\n")
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree: %v", err)
+	}
+	for _, name := range []string{"tmpl/dropped.txt", "tmpl/edited.txt"} {
+		if _, err := w.Remove(name); err != nil {
+			t.Fatalf("failed to remove %s: %v", name, err)
+		}
+	}
+	commitFile(t, r, repoDir, "tmpl/empty.txt", "")
+	tag("v2")
+
+	source := "file://" + repoDir + "//tmpl@v1"
+	data := map[string]string{"Name": "app"}
+
+	out := templit.NewMemOutput()
+	executor := templit.NewExecutor(templit.NewDefaultGitClient("main", ""), templit.WithOutput(out))
+
+	if _, err := executor.ImportFunc("out")(source, ".", data); err != nil {
+		t.Fatalf("failed to render v1: %v", err)
+	}
+
+	// local changes
+	for name, content := range map[string]string{
+		"out/config.txt": "header\nname=app\n1\n2 local\n3\n4\nfooter local\n",
+		"out/edited.txt": "edited locally\n",
+	} {
+		if err := out.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := out.Remove("out/local.txt"); err != nil {
+		t.Fatalf("failed to remove local.txt: %v", err)
+	}
+
+	result, err := executor.Update(source, "v2", "out", data, data)
+	if err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+
+	expectedResult := &templit.UpdateResult{
+		Added:     []string{"added.txt", "empty.txt"},
+		Conflicts: []string{"config.txt"},
+		Removed:   []string{"dropped.txt"},
+		Skipped:   []string{"local.txt"},
+	}
+
+	if diff := cmp.Diff(expectedResult, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	expectedFiles := map[string]string{
+		"added.txt":  "added app\n",
+		"config.txt": "header v2\nname=app\n1\n2 local\n3\n4\n<<<<<<< local\nfooter local\n=======\nfooter v2\n>>>>>>> template v2\n",
+		"edited.txt": "edited locally\n",
+		"empty.txt":  "",
+	}
+
+	if diff := cmp.Diff(expectedFiles, memFiles(out)); diff != "" {
+		t.Errorf("files mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// countingDefaultGitClient is a DefaultGitClient that counts clones.
+type countingDefaultGitClient struct {
+	*templit.DefaultGitClient
+	clones int
+}
+
+// Clone clones a repository to the given destination and counts the call.
+func (c *countingDefaultGitClient) Clone(host, owner, repo, ref, dest string) error {
+	c.clones++
+	return c.DefaultGitClient.Clone(host, owner, repo, ref, dest)
+}
+
+// TestExecutor_UpdateSingleClone tests that update checks out both template versions from one clone.
+func TestExecutor_UpdateSingleClone(t *testing.T) {
+	base := t.TempDir()
+	repoDir := filepath.Join(base, "owner", "repo.git")
+
+	r, err := git.PlainInit(repoDir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+
+	commitFile(t, r, repoDir, "tmpl/main.txt", "v1 {{.Name}}\n")
+	head, err := r.Head()
+	if err != nil {
+		t.Fatalf("failed to read head: %v", err)
+	}
+	if _, err := r.CreateTag("v1", head.Hash(), nil); err != nil {
+		t.Fatalf("failed to tag: %v", err)
+	}
+	commitFile(t, r, repoDir, "tmpl/main.txt", "v2 {{.Name}}\n")
+
+	client := &countingDefaultGitClient{DefaultGitClient: templit.NewDefaultGitClient("master", "")}
+	client.Rewrites = []templit.Rewrite{{URL: "file://" + base + "/", InsteadOf: "github.com/"}}
+
+	out := templit.NewMemOutput()
+	out.Root = "out"
+	if err := out.WriteFile("out/main.txt", []byte("v1 app\n"), 0644); err != nil {
+		t.Fatalf("failed to write main.txt: %v", err)
+	}
+
+	data := map[string]string{"Name": "app"}
+	executor := templit.NewExecutor(client, templit.WithOutput(out))
+	result, err := executor.Update("github.com/owner/repo//tmpl@v1", "master", "out", data, data)
+	if err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+
+	if diff := cmp.Diff(&templit.UpdateResult{Updated: []string{"main.txt"}}, result); diff != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff("v2 app\n", string(out.Files["main.txt"].Data)); diff != "" {
+		t.Errorf("content mismatch (-want +got):\n%s", diff)
+	}
+
+	if client.clones != 1 {
+		t.Errorf("expected 1 clone, got %d", client.clones)
+	}
+}
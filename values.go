@@ -0,0 +1,171 @@
+package templit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadValues reads the render data in the file at path. The format is chosen by the file extension:
+// YAML (.yaml, .yml), TOML (.toml), JSON (.json) or dotenv (.env, or files named like .env.local),
+// whose values are strings.
+func LoadValues(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values: %w", err)
+	}
+
+	values := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".yaml" || ext == ".yml":
+		err = yaml.Unmarshal(content, &values)
+	case ext == ".toml":
+		err = toml.Unmarshal(content, &values)
+	case ext == ".json":
+		err = json.Unmarshal(content, &values)
+	case ext == ".env" || strings.HasPrefix(filepath.Base(path), ".env."):
+		values, err = parseDotenv(content)
+	default:
+		return nil, fmt.Errorf("failed to load values from %s: unknown format %q", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse values from %s: %w", path, err)
+	}
+
+	// empty YAML and JSON documents decode to nil
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	return values, nil
+}
+
+// parseDotenv parses KEY=VALUE lines. Blank lines, comments starting with "#" and an "export " prefix are ignored.
+// Values in double quotes are unquoted like Go strings, values in single quotes are taken literally.
+func parseDotenv(content []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE, got %q", n, line)
+		}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value %s", n, value)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		}
+
+		values[key] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// MergeValues deep merges src into dst and returns dst, which is allocated if it is nil. Maps present in both
+// are merged recursively without modifying the maps of dst, all other values of src replace those of dst.
+func MergeValues(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			if dstMap, ok := dst[k].(map[string]interface{}); ok {
+				dst[k] = MergeValues(maps.Clone(dstMap), srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+
+	return dst
+}
+
+// SetValue sets the value of an assignment like "db.port=5432" in values. The dotted key creates nested maps,
+// replacing values that are not maps without modifying the maps of values. Like Helm's --set, "true" and "false"
+// are bools, "null" is nil and integers without a leading zero are ints. Any other value, including floats like
+// "1.20" and numbers like "007", is kept as a string.
+func SetValue(values map[string]interface{}, assignment string) error {
+	key, raw, ok := strings.Cut(assignment, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid value %q, expected <key>=<value>", assignment)
+	}
+
+	path := strings.Split(key, ".")
+	for _, name := range path {
+		if name == "" {
+			return fmt.Errorf("invalid key %q", key)
+		}
+	}
+
+	for _, name := range path[:len(path)-1] {
+		child, ok := values[name].(map[string]interface{})
+		if ok {
+			child = maps.Clone(child)
+		} else {
+			child = map[string]interface{}{}
+		}
+		values[name] = child
+		values = child
+	}
+
+	values[path[len(path)-1]] = parseSetValue(raw)
+	return nil
+}
+
+// parseSetValue parses the value of a SetValue assignment.
+func parseSetValue(raw string) interface{} {
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	// leading zeros are significant, like in ids and file modes
+	if raw == "0" || !strings.HasPrefix(raw, "0") {
+		if i, err := strconv.Atoi(raw); err == nil {
+			return i
+		}
+	}
+
+	return raw
+}
+
+// EnvValues returns the environment variables in environ, a list of "KEY=VALUE" strings like os.Environ returns,
+// as render data by their name.
+func EnvValues(environ []string) map[string]interface{} {
+	values := make(map[string]interface{}, len(environ))
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok && key != "" {
+			values[key] = value
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,143 @@
+package templit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/euforic/templit"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestLoadValues tests loading render data from files in each supported format.
+func TestLoadValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "yaml",
+			file:     "values.yaml",
+			content:  "name: api\nport: 8080\ndb:\n  host: localhost\ntags: [a, b]\n",
+			expected: map[string]interface{}{"name": "api", "port": 8080, "db": map[string]interface{}{"host": "localhost"}, "tags": []interface{}{"a", "b"}},
+		},
+		{
+			name:     "toml",
+			file:     "values.toml",
+			content:  "name = \"api\"\nport = 8080\n\n[db]\nhost = \"localhost\"\n",
+			expected: map[string]interface{}{"name": "api", "port": int64(8080), "db": map[string]interface{}{"host": "localhost"}},
+		},
+		{
+			name:     "json",
+			file:     "values.json",
+			content:  `{"name": "api", "port": 8080, "db": {"host": "localhost"}}`,
+			expected: map[string]interface{}{"name": "api", "port": float64(8080), "db": map[string]interface{}{"host": "localhost"}},
+		},
+		{
+			name:     "dotenv",
+			file:     ".env.local",
+			content:  "# database\nexport DB_HOST=localhost\nDB_NAME=\"app\\tdb\"\nDB_PASS='p#ss'\n\nEMPTY=\n",
+			expected: map[string]interface{}{"DB_HOST": "localhost", "DB_NAME": "app\tdb", "DB_PASS": "p#ss", "EMPTY": ""},
+		},
+		{
+			name:     "empty yaml",
+			file:     "empty.yml",
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write values: %v", err)
+			}
+
+			values, err := templit.LoadValues(path)
+			if err != nil {
+				t.Fatalf("failed to load values: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.expected, values); diff != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := templit.LoadValues(filepath.Join(t.TempDir(), "values.ini")); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+// TestMergeValues tests deep merging render data and overriding it with set values.
+func TestMergeValues(t *testing.T) {
+	base := map[string]interface{}{
+		"name": "api",
+		"db":   map[string]interface{}{"host": "localhost", "port": 5432},
+	}
+
+	values := templit.MergeValues(nil, base)
+	values = templit.MergeValues(values, map[string]interface{}{
+		"db":   map[string]interface{}{"host": "db.internal"},
+		"tags": []interface{}{"a"},
+	})
+
+	for _, assignment := range []string{
+		"db.port=6543",
+		"db.ssl=true",
+		"replicas=3",
+		"offset=-2",
+		"ratio=0.5",
+		"version=1.20",
+		"build=007",
+		"size=1e3",
+		"proxy=null",
+		"tags=[\"b\",\"c\"]",
+		"owner.name=ops team",
+		"name.full=api server",
+	} {
+		if err := templit.SetValue(values, assignment); err != nil {
+			t.Fatalf("failed to set %q: %v", assignment, err)
+		}
+	}
+
+	expected := map[string]interface{}{
+		"name":     map[string]interface{}{"full": "api server"},
+		"db":       map[string]interface{}{"host": "db.internal", "port": 6543, "ssl": true},
+		"tags":     `["b","c"]`,
+		"replicas": 3,
+		"offset":   -2,
+		"ratio":    "0.5",
+		"version":  "1.20",
+		"build":    "007",
+		"size":     "1e3",
+		"proxy":    nil,
+		"owner":    map[string]interface{}{"name": "ops team"},
+	}
+	if diff := cmp.Diff(expected, values); diff != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", diff)
+	}
+
+	// merging and setting must not modify the nested maps of the merged data
+	if diff := cmp.Diff(map[string]interface{}{"host": "localhost", "port": 5432}, base["db"]); diff != "" {
+		t.Errorf("base modified (-want +got):\n%s", diff)
+	}
+
+	for _, assignment := range []string{"db", "=1", "db..port=1"} {
+		if err := templit.SetValue(values, assignment); err == nil {
+			t.Errorf("expected an error for %q", assignment)
+		}
+	}
+}
+
+// TestEnvValues tests exposing environment variables as render data.
+func TestEnvValues(t *testing.T) {
+	values := templit.EnvValues([]string{"HOME=/root", "EMPTY=", "OPTS=a=b", "=C:=C:\\"})
+
+	expected := map[string]interface{}{"HOME": "/root", "EMPTY": "", "OPTS": "a=b"}
+	if diff := cmp.Diff(expected, values); diff != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", diff)
+	}
+}
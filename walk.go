@@ -2,87 +2,317 @@ package templit
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"text/template"
+	"text/template/parse"
 )
 
 // WalkAndProcessDir processes all files in a directory with the given data.
-// If walkFunc is provided, it's called for each file and directory without writing the file to disk.
+// File and directory names are rendered as templates; entries whose name renders empty or starts with "-" are skipped.
+// A name with a range action, like "{{range .Services}}{{.Name}}{{end}}", is generated once per element with the
+// element as its data. Paths excluded by the IgnoreFileName files of the template tree are skipped.
+// Files generated by a previous run but not by this one are pruned as configured by WithPrune.
 func (e *Executor) WalkAndProcessDir(inputDir, outputDir string, data interface{}) error {
+	if err := e.pruneAfter(outputDir, func() error {
+		return e.walkFS(&walker{fsys: os.DirFS(inputDir), prefix: inputDir, root: outputDir}, ".", outputDir, data)
+	}); err != nil {
+		return fmt.Errorf("error walking through directory: %w", err)
+	}
+
+	return nil
+}
+
+// WalkAndProcessFS processes all files below the directory root of fsys, such as an embed.FS, with the given data
+// and writes them to outputDir. Names are rendered like in WalkAndProcessDir and templates are named by their
+// slash separated path in fsys. Stale files are pruned like in WalkAndProcessDir.
+func (e *Executor) WalkAndProcessFS(fsys fs.FS, root, outputDir string, data interface{}) error {
+	if err := e.pruneAfter(outputDir, func() error {
+		return e.walkFS(&walker{fsys: fsys, root: outputDir}, root, outputDir, data)
+	}); err != nil {
+		return fmt.Errorf("error walking through directory: %w", err)
+	}
+
+	return nil
+}
+
+// walker holds the state shared while rendering a template tree.
+type walker struct {
+	fsys fs.FS
+	// prefix prefixes the names of the parsed templates.
+	prefix string
+	// root is the output directory that conflict rules are matched relative to.
+	root string
+	// base is the template directory that copy-only globs and ignore files are matched relative to.
+	base string
+	// ignore holds the patterns of the ignore files found while walking.
+	ignore ignorer
+}
+
+// walkFS processes all files below dir in the fs of w with the given data and writes them to outputDir.
+func (e *Executor) walkFS(w *walker, dir, outputDir string, data interface{}) error {
+	w.base = dir
+	e.setOutputRoot(w.root)
+	e.walkDepth++
+	defer func() { e.walkDepth-- }()
+
+	// the spec at the template root fills in defaults and validates the data
+	spec, err := LoadSpec(w.fsys, dir)
+	if err != nil {
+		return err
+	}
+	if spec != nil {
+		if data, err = spec.applyTo(data); err != nil {
+			return err
+		}
+
+		// defaults and conditions of the spec reference fields too
+		for _, v := range spec.Variables {
+			e.recordUsage(SpecFileName, v.When, "", "")
+			if def, ok := v.Default.(string); ok {
+				e.recordUsage(SpecFileName, def, "", "")
+			}
+		}
+	}
+
+	if err := e.validateData(w.fsys, dir, data, e.walkDepth > 1); err != nil {
+		return err
+	}
+
 	// Create output directory
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
+	if err := e.out.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return fmt.Errorf("error walking through directory: %w", err)
+	return e.processDir(w, dir, outputDir, data)
+}
+
+// processDir renders the entries of dir into outDir.
+func (e *Executor) processDir(w *walker, dir, outDir string, data interface{}) error {
+	entries, err := fs.ReadDir(w.fsys, dir)
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+
+	if err := w.ignore.load(w.fsys, dir, relPath(w.base, dir)); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() == ".git" {
+			continue
 		}
 
-		parsedName, err := e.StringRender(filepath.Base(path), data)
-		if err != nil {
-			return fmt.Errorf("error rendering path template: %w", err)
+		if w.ignore.ignored(relPath(w.base, path.Join(dir, entry.Name())), entry.IsDir()) {
+			continue
 		}
 
-		relPath, err := filepath.Rel(inputDir, filepath.Dir(path))
+		names, err := e.expandName(entry.Name(), data)
 		if err != nil {
-			return fmt.Errorf("error getting relative path: %w", err)
+			return fmt.Errorf("error rendering path template: %w", err)
 		}
 
-		outPath := filepath.Join(outputDir, relPath, parsedName)
-		parsedOutPath, err := e.StringRender(outPath, data)
+		info, err := entry.Info()
 		if err != nil {
-			return fmt.Errorf("error rendering path template: %w", err)
+			return fmt.Errorf("error reading file info: %w", err)
 		}
 
-		if info.IsDir() {
-			// Skip directories with empty or "-" prefixed names
-			if parsedName == "" || strings.HasPrefix(parsedName, "-") {
-				return filepath.SkipDir
-			}
+		entryPath := path.Join(dir, entry.Name())
 
-			// Skip root directory
-			if filepath.Base(outPath) == filepath.Base(inputDir) {
-				return nil
+		for _, name := range names {
+			// Skip entries with empty or "-" prefixed names
+			if name.name == "" || strings.HasPrefix(name.name, "-") {
+				continue
 			}
 
-			if err := os.MkdirAll(parsedOutPath, info.Mode()); err != nil {
-				return fmt.Errorf("error creating directory: %w", err)
-			}
+			outPath := filepath.Join(outDir, name.name)
 
-			return nil
+			e.setSource(outPath, templateName(w.prefix, entryPath))
+
+			// the fields of a range element are not top-level data fields
+			if name.element {
+				e.elementDepth++
+			}
+			err := e.processEntry(w, entryPath, outPath, info, name.data)
+			if name.element {
+				e.elementDepth--
+			}
+			if err != nil {
+				return err
+			}
 		}
+	}
+
+	return nil
+}
+
+// processEntry generates the file or directory at name of the template tree as outPath.
+func (e *Executor) processEntry(w *walker, name, outPath string, info fs.FileInfo, data interface{}) error {
+	if !info.IsDir() {
+		return e.processFile(w, name, outPath, info.Mode(), data)
+	}
+
+	if err := e.out.MkdirAll(outPath, info.Mode().Perm()|0700); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	return e.processDir(w, name, outPath, data)
+}
+
+// expandedName is an output name of a template entry and the data the entry is rendered with.
+type expandedName struct {
+	name string
+	data interface{}
+	// element reports whether data is an element of a range in the name
+	element bool
+}
+
+// expandName renders the file or directory name with data. A name containing a range action, such as
+// "{{range .Services}}{{.Name}}{{end}}", expands to one name per element, and the element becomes the data
+// of the file or of the directory's subtree. Text and actions around the range are rendered with data.
+func (e *Executor) expandName(name string, data interface{}) ([]expandedName, error) {
+	tmpl, err := e.New("temp").Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+	e.recordUsage(name, name, e.leftDelim, e.rightDelim)
+
+	// split the name around its first range action without an else branch
+	var loop *parse.RangeNode
+	var before, after strings.Builder
+	if tmpl.Tree != nil {
+		for _, node := range tmpl.Tree.Root.Nodes {
+			if r, ok := node.(*parse.RangeNode); ok && loop == nil && r.ElseList == nil {
+				loop = r
+				continue
+			}
 
-		// Skip files with empty names or "-" prefixed
-		if parsedName == "" || strings.HasPrefix(parsedName, "-") {
-			return nil
+			if loop == nil {
+				before.WriteString(node.String())
+			} else {
+				after.WriteString(node.String())
+			}
 		}
+	}
 
-		content, err := os.ReadFile(path)
+	if loop == nil {
+		parsedName, err := e.StringRender(name, data)
 		if err != nil {
-			return fmt.Errorf("error reading file from templates: %w", err)
+			return nil, err
 		}
 
-		tmpl, err := e.New(path).Parse(string(content))
+		return []expandedName{{name: parsedName, data: data}}, nil
+	}
+
+	// the element of every iteration is collected while the range renders the names, separated by NUL
+	var elems []interface{}
+	clone, err := e.Clone()
+	if err != nil {
+		return nil, err
+	}
+	clone.Funcs(template.FuncMap{"templitElement": func(elem interface{}) string {
+		elems = append(elems, elem)
+		return ""
+	}})
+
+	// the parse tree prints actions with the delimiters it was parsed with, which the clone keeps
+	render := func(text string) (string, error) {
+		tmpl, err := clone.New("temp").Parse(text)
 		if err != nil {
-			return fmt.Errorf("error parsing template: %w", err)
+			return "", fmt.Errorf("error parsing template: %w", err)
 		}
 
 		var buf strings.Builder
 		if err := tmpl.Execute(&buf, data); err != nil {
-			return fmt.Errorf("error executing template: %w", err)
+			return "", fmt.Errorf("error executing template: %w", err)
 		}
 
-		if err := os.WriteFile(parsedOutPath, []byte(buf.String()), info.Mode()); err != nil {
+		return buf.String(), nil
+	}
+
+	prefix, err := render(before.String())
+	if err != nil {
+		return nil, err
+	}
+
+	suffix, err := render(after.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// the range is rebuilt around the collector with the delimiters of the executor, empty ones are the defaults
+	left, right := e.delims(nil)
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+
+	loopNames, err := render(left + "range " + loop.Pipe.String() + right + left + "templitElement ." + right + loop.List.String() + "\x00" + left + "end" + right)
+	if err != nil {
+		return nil, err
+	}
+
+	loopParts := strings.Split(loopNames, "\x00")
+	if len(loopParts) != len(elems)+1 {
+		return nil, fmt.Errorf("range in path %q must render one name per element", name)
+	}
+
+	names := make([]expandedName, len(elems))
+	for i, elem := range elems {
+		names[i] = expandedName{name: prefix + loopParts[i] + suffix, data: elem, element: true}
+	}
+
+	return names, nil
+}
+
+// processFile renders the template at name and writes the result to outPath.
+// The front matter of the template may skip the file or change its path, mode and conflict policy.
+func (e *Executor) processFile(w *walker, name, outPath string, mode fs.FileMode, data interface{}) error {
+	content, err := fs.ReadFile(w.fsys, name)
+	if err != nil {
+		return fmt.Errorf("error reading file from templates: %w", err)
+	}
+
+	// binary and copy-only files are copied verbatim
+	if e.isCopyOnly(relPath(w.base, name), content) {
+		if err := e.writeFile(w.root, outPath, content, mode.Perm()|0200, ""); err != nil {
 			return fmt.Errorf("error writing file to output: %w", err)
 		}
-
 		return nil
-	})
+	}
 
-	if err != nil {
-		return fmt.Errorf("error walking through directory: %w", err)
+	fm, rendered, ok, err := e.renderFile(templateName(w.prefix, name), content, data)
+	if err != nil || !ok {
+		return err
+	}
+
+	// generated files stay writable by their owner even if the source, like an embed.FS, is read-only,
+	// unless the front matter sets their mode
+	perm := mode.Perm() | 0200
+
+	var policy ConflictPolicy
+	if fm != nil {
+		filePath := outPath
+		if outPath, perm, policy, err = e.applyFrontMatter(fm, w.root, outPath, perm, data); err != nil {
+			return err
+		}
+
+		if outPath != filePath {
+			e.setSource(outPath, templateName(w.prefix, name))
+
+			if err := e.out.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+				return fmt.Errorf("error creating directory: %w", err)
+			}
+		}
+	}
+
+	if err := e.writeFile(w.root, outPath, rendered, perm, policy); err != nil {
+		return fmt.Errorf("error writing file to output: %w", err)
 	}
 
 	return nil
@@ -1,10 +1,14 @@
 package templit_test
 
 import (
+	"embed"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"text/template"
 
 	"github.com/euforic/templit"
@@ -113,3 +117,149 @@ func TestWalkAndProcessDir(t *testing.T) {
 		})
 	}
 }
+
+//go:embed test_data/templates/basic_test
+var basicTestFS embed.FS
+
+// TestWalkAndProcessFS tests the WalkAndProcessFS function.
+func TestWalkAndProcessFS(t *testing.T) {
+	tests := []struct {
+		name           string
+		fsys           fs.FS
+		root           string
+		data           interface{}
+		expectedOutput string
+		expectedFiles  map[string]string
+		// unexpectedFiles must not be generated
+		unexpectedFiles []string
+		expectedError   string
+	}{
+		{
+			name: "embed.FS",
+			fsys: basicTestFS,
+			root: "test_data/templates/basic_test",
+			data: map[string]interface{}{
+				"Name":        "John",
+				"Title":       "Project",
+				"Description": "This is a test project.",
+				"Detail":      "more info here.",
+			},
+			expectedOutput: "test_data/outputs/basic_test/",
+		},
+		{
+			name: "fstest.MapFS",
+			fsys: fstest.MapFS{
+				"app/{{.Name}}.txt":  {Data: []byte("Hello, {{.Name}}!")},
+				"app/docs/README.md": {Data: []byte("# {{.Name}}")},
+			},
+			root: "app",
+			data: map[string]string{"Name": "john"},
+			expectedFiles: map[string]string{
+				"john.txt":       "Hello, john!",
+				"docs/README.md": "# john",
+			},
+		},
+		{
+			name: "range in names",
+			fsys: fstest.MapFS{
+				"app/services/{{range .Services}}{{.Name}}{{end}}/main.go": {Data: []byte("package {{.Name}} // port {{.Port}}")},
+				"app/{{.Project}}-{{range .Envs}}{{.}}{{end}}.env":         {Data: []byte("ENV={{.}}")},
+				"app/{{range .Empty}}{{.}}{{end}}/skipped.txt":             {Data: []byte("skipped")},
+			},
+			root: "app",
+			data: map[string]interface{}{
+				"Project": "shop",
+				"Services": []map[string]interface{}{
+					{"Name": "api", "Port": 8080},
+					{"Name": "worker", "Port": 9090},
+				},
+				"Envs":  []string{"dev", "prod"},
+				"Empty": []string{},
+			},
+			expectedFiles: map[string]string{
+				"services/api/main.go":    "package api // port 8080",
+				"services/worker/main.go": "package worker // port 9090",
+				"shop-dev.env":            "ENV=dev",
+				"shop-prod.env":           "ENV=prod",
+			},
+		},
+		{
+			name: "templitignore",
+			fsys: fstest.MapFS{
+				"app/.templitignore":          {Data: []byte("# template repository files\nREADME.md\n/ci/\n*.bak\n")},
+				"app/README.md":               {Data: []byte("template readme")},
+				"app/ci/build.yml":            {Data: []byte("ci")},
+				"app/main.txt":                {Data: []byte("main {{.Name}}")},
+				"app/main.txt.bak":            {Data: []byte("backup")},
+				"app/docs/README.md":          {Data: []byte("docs readme")},
+				"app/docs/ci/notes.txt":       {Data: []byte("notes")},
+				"app/docs/.templitignore":     {Data: []byte("fixtures/\n!keep.bak\n")},
+				"app/docs/keep.bak":           {Data: []byte("kept")},
+				"app/docs/fixtures/data.json": {Data: []byte("{{")},
+			},
+			root: "app",
+			data: map[string]string{"Name": "john"},
+			expectedFiles: map[string]string{
+				"main.txt":          "main john",
+				"docs/ci/notes.txt": "notes",
+				"docs/keep.bak":     "kept",
+			},
+			unexpectedFiles: []string{
+				".templitignore",
+				"README.md",
+				"ci/build.yml",
+				"main.txt.bak",
+				"docs/README.md",
+				"docs/.templitignore",
+				"docs/fixtures/data.json",
+			},
+		},
+		{
+			name:          "missing root",
+			fsys:          fstest.MapFS{},
+			root:          "missing",
+			expectedError: "error walking through directory: error reading directory: open missing: file does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+
+			executor := templit.NewExecutor(nil)
+			err := executor.WalkAndProcessFS(tt.fsys, tt.root, outputDir, tt.data)
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to process fs: %v", err)
+			}
+
+			if tt.expectedOutput != "" {
+				if err := compareDirs(tt.expectedOutput, outputDir); err != nil {
+					t.Fatalf("output directory mismatch: %v", err)
+				}
+			}
+
+			for name, expected := range tt.expectedFiles {
+				content, err := os.ReadFile(filepath.Join(outputDir, name))
+				if err != nil {
+					t.Fatalf("failed to read %s: %v", name, err)
+				}
+
+				if string(content) != expected {
+					t.Errorf("expected %s to be %q, got %q", name, expected, content)
+				}
+			}
+
+			for _, name := range tt.unexpectedFiles {
+				if _, err := os.Stat(filepath.Join(outputDir, name)); !os.IsNotExist(err) {
+					t.Errorf("expected %s not to be generated, got %v", name, err)
+				}
+			}
+		})
+	}
+}